@@ -0,0 +1,274 @@
+package adt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/bitutil"
+	"github.com/apache/arrow/go/arrow/decimal128"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+// julianUnixEpoch is the Julian day number of 1970-01-01, the epoch of Arrow
+// dates and timestamps.
+const julianUnixEpoch = 2440588
+
+const msPerDay = 24 * 60 * 60 * 1000
+
+// Null sentinels stored in fixed-width columns; see ReadValue.
+var doubleNullBits = math.Float64bits(-1.6e-322)
+
+// ArrowSchema returns the Arrow schema for t's columns. Dates, times and
+// timestamps keep the wall clock as stored, so timestamps carry no time zone.
+func (t *Table) ArrowSchema() (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(t.Columns))
+	for i, c := range t.Columns {
+		typ, err := arrowType(c)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = arrow.Field{Name: c.Name, Type: typ, Nullable: c.Nullable()}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func arrowType(c *Column) (arrow.DataType, error) {
+	switch c.Type {
+	case ColumnTypeCharacter, ColumnTypeCiCharacter, ColumnTypeMemo:
+		return arrow.BinaryTypes.String, nil
+	case ColumnTypeBlob:
+		return arrow.BinaryTypes.Binary, nil
+	case ColumnTypeBool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case ColumnTypeShortInt:
+		return arrow.PrimitiveTypes.Int16, nil
+	case ColumnTypeInt:
+		return arrow.PrimitiveTypes.Int32, nil
+	case ColumnTypeAutoIncrement:
+		return arrow.PrimitiveTypes.Uint32, nil
+	case ColumnTypeRowVersion:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case ColumnTypeDouble:
+		return arrow.PrimitiveTypes.Float64, nil
+	case ColumnTypeCurrency:
+		return &arrow.Decimal128Type{Precision: CurrencyPrecision, Scale: int32(c.CurrencyScale())}, nil
+	case ColumnTypeDate:
+		return arrow.FixedWidthTypes.Date32, nil
+	case ColumnTypeTime:
+		return arrow.FixedWidthTypes.Time32ms, nil
+	case ColumnTypeTimestamp, ColumnTypeModTime:
+		return &arrow.TimestampType{Unit: arrow.Millisecond}, nil
+	}
+	return nil, fmt.Errorf("adt: no Arrow type for column %s", c)
+}
+
+// ArrowReader returns a reader producing record batches of up to batchSize
+// rows. Fixed-width columns are decoded from the raw record bytes straight
+// into Arrow buffers without going through Record. The reader shares t's
+// file position, so t should not be read from while batches are produced.
+func (t *Table) ArrowReader(batchSize int) (*ArrowReader, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("adt: invalid batch size %d", batchSize)
+	}
+	schema, err := t.ArrowSchema()
+	if err != nil {
+		return nil, err
+	}
+	return &ArrowReader{
+		t:         t,
+		schema:    schema,
+		mem:       memory.NewGoAllocator(),
+		batchSize: batchSize,
+		refs:      1,
+	}, nil
+}
+
+// ArrowReader reads a Table as a stream of Arrow records. It implements
+// array.RecordReader.
+type ArrowReader struct {
+	t         *Table
+	schema    *arrow.Schema
+	mem       memory.Allocator
+	batchSize int
+	next      int
+	refs      int64
+	cur       array.Record
+	err       error
+}
+
+var _ array.RecordReader = (*ArrowReader)(nil)
+
+// Retain increases the reference count of r.
+func (r *ArrowReader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+// Release decreases the reference count of r, releasing the current record
+// when it reaches zero.
+func (r *ArrowReader) Release() {
+	if atomic.AddInt64(&r.refs, -1) == 0 && r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+}
+
+// Schema returns the schema of the records produced by r.
+func (r *ArrowReader) Schema() *arrow.Schema { return r.schema }
+
+// Record returns the current batch. It is only valid until the next call to
+// Next; callers that keep it must Retain it.
+func (r *ArrowReader) Record() array.Record { return r.cur }
+
+// Err returns the error, if any, that stopped Next.
+func (r *ArrowReader) Err() error { return r.err }
+
+// Next reads the next batch, reporting whether one was read.
+func (r *ArrowReader) Next() bool {
+	if r.cur != nil {
+		r.cur.Release()
+		r.cur = nil
+	}
+	if r.err != nil || r.next >= int(r.t.RecordCount) {
+		return false
+	}
+	n := int(r.t.RecordCount) - r.next
+	if n > r.batchSize {
+		n = r.batchSize
+	}
+	raw, err := r.t.readRaw(r.next, n)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	cols := make([]array.Interface, len(r.t.Columns))
+	for i, c := range r.t.Columns {
+		if cols[i], err = r.column(c, raw, n); err != nil {
+			for _, col := range cols[:i] {
+				col.Release()
+			}
+			r.err = err
+			return false
+		}
+	}
+	r.cur = array.NewRecord(r.schema, cols, int64(n))
+	for _, col := range cols {
+		col.Release()
+	}
+	r.next += n
+	return true
+}
+
+// column builds the array holding c's values for the n records in raw.
+func (r *ArrowReader) column(c *Column, raw []byte, n int) (array.Interface, error) {
+	field := func(i int) []byte {
+		start := i*int(r.t.RecordLength) + int(c.Offset)
+		return raw[start : start+int(c.Length)]
+	}
+	switch c.Type {
+	case ColumnTypeCharacter, ColumnTypeCiCharacter:
+		b := array.NewStringBuilder(r.mem)
+		defer b.Release()
+		for i := 0; i < n; i++ {
+			b.Append(strings.Trim(DecodeString(field(i)), " \u0000"))
+		}
+		return b.NewArray(), nil
+	case ColumnTypeMemo:
+		b := array.NewStringBuilder(r.mem)
+		defer b.Release()
+		for i := 0; i < n; i++ {
+			f := field(i)
+			data, err := r.t.readMemo(MemoField{
+				BlockOffset: binary.LittleEndian.Uint32(f),
+				Length:      binary.LittleEndian.Uint16(f[4:]),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("adt: reading memo %s: %v", c.Name, err)
+			}
+			b.Append(DecodeString(data))
+		}
+		return b.NewArray(), nil
+	case ColumnTypeBlob:
+		b := array.NewBinaryBuilder(r.mem, arrow.BinaryTypes.Binary)
+		defer b.Release()
+		for i := 0; i < n; i++ {
+			b.Append(field(i))
+		}
+		return b.NewArray(), nil
+	}
+
+	typ, err := arrowType(c)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		width = typ.(arrow.FixedWidthDataType).BitWidth() / 8
+		data  = make([]byte, n*width)
+		valid = make([]byte, bitutil.BytesForBits(int64(n)))
+		nulls = 0
+	)
+	switch c.Type {
+	case ColumnTypeBool:
+		data = make([]byte, bitutil.BytesForBits(int64(n)))
+	case ColumnTypeCurrency:
+		// Decimal128Type reports its width in bytes rather than bits.
+		width = arrow.Decimal128SizeBytes
+		data = make([]byte, n*width)
+	}
+	for i := 0; i < n; i++ {
+		f := field(i)
+		dst := data[i*width : (i+1)*width]
+		null := false
+		switch c.Type {
+		case ColumnTypeBool:
+			bitutil.SetBitTo(data, i, f[0] == 'T')
+		case ColumnTypeShortInt:
+			null = int16(binary.LittleEndian.Uint16(f)) == math.MinInt16
+			copy(dst, f)
+		case ColumnTypeInt:
+			null = int32(binary.LittleEndian.Uint32(f)) == math.MinInt32
+			copy(dst, f)
+		case ColumnTypeAutoIncrement, ColumnTypeRowVersion:
+			copy(dst, f)
+		case ColumnTypeDouble:
+			null = binary.LittleEndian.Uint64(f) == doubleNullBits
+			copy(dst, f)
+		case ColumnTypeTime:
+			// -1 marks an empty time, which Record reports as zero.
+			if int32(binary.LittleEndian.Uint32(f)) != -1 {
+				copy(dst, f)
+			}
+		case ColumnTypeDate:
+			day := int32(binary.LittleEndian.Uint32(f))
+			null = day == 0
+			binary.LittleEndian.PutUint32(dst, uint32(day-julianUnixEpoch))
+		case ColumnTypeTimestamp, ColumnTypeModTime:
+			day := int32(binary.LittleEndian.Uint32(f))
+			ms := int32(binary.LittleEndian.Uint32(f[4:]))
+			null = day == 0
+			binary.LittleEndian.PutUint64(dst, uint64(int64(day-julianUnixEpoch)*msPerDay+int64(ms)))
+		case ColumnTypeCurrency:
+			bits := binary.LittleEndian.Uint64(f)
+			null = bits == doubleNullBits
+			v := decimal128.FromI64(int64(math.Round(math.Float64frombits(bits) * math.Pow10(c.CurrencyScale()))))
+			binary.LittleEndian.PutUint64(dst, v.LowBits())
+			binary.LittleEndian.PutUint64(dst[8:], uint64(v.HighBits()))
+		}
+		if null {
+			nulls++
+		} else {
+			bitutil.SetBit(valid, i)
+		}
+	}
+	buffers := []*memory.Buffer{nil, memory.NewBufferBytes(data)}
+	if nulls > 0 {
+		buffers[0] = memory.NewBufferBytes(valid)
+	}
+	d := array.NewData(typ, n, buffers, nil, nulls, 0)
+	defer d.Release()
+	return array.MakeFromData(d), nil
+}
@@ -0,0 +1,123 @@
+package adt_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/tmc/adt"
+)
+
+// testADT builds an in-memory table with the given columns and raw records.
+func testADT(t *testing.T, columns []*adt.Column, records [][]byte) *adt.Table {
+	t.Helper()
+	table, err := adt.FromReaders(bytes.NewReader(adtBytes(columns, records)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+// adtBytes returns the contents of an ADT file holding records, each of
+// which is prefixed with the active record flags.
+func adtBytes(columns []*adt.Column, records [][]byte) []byte {
+	recordLength := 5
+	for _, c := range columns {
+		recordLength += int(c.Length)
+	}
+	dataOffset := adt.HeaderLength + adt.ColumnDescriptorLength*len(columns)
+	buf := make([]byte, dataOffset)
+	copy(buf, adt.MagicHeader)
+	binary.LittleEndian.PutUint32(buf[24:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(buf[32:], uint16(dataOffset))
+	binary.LittleEndian.PutUint32(buf[36:], uint32(recordLength))
+	for i, c := range columns {
+		d := buf[adt.HeaderLength+adt.ColumnDescriptorLength*i:]
+		copy(d, c.Name)
+		d[129] = byte(c.Type)
+		binary.LittleEndian.PutUint16(d[131:], c.Offset)
+		binary.BigEndian.PutUint16(d[134:], c.Length)
+		binary.BigEndian.PutUint16(d[138:], c.DecimalDigits)
+	}
+	for _, r := range records {
+		rec := make([]byte, recordLength)
+		copy(rec, adt.RecordMagicHeader)
+		copy(rec[5:], r)
+		buf = append(buf, rec...)
+	}
+	return buf
+}
+
+func le32(v int32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func TestArrowReader(t *testing.T) {
+	columns := []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Offset: 5, Length: 4},
+		{Name: "N", Type: adt.ColumnTypeInt, Offset: 9, Length: 4},
+		{Name: "BORN", Type: adt.ColumnTypeDate, Offset: 13, Length: 4},
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 17, Length: 6},
+		{Name: "OK", Type: adt.ColumnTypeBool, Offset: 23, Length: 1},
+	}
+	record := func(id, n, day int32, name string, ok byte) []byte {
+		var b []byte
+		b = append(b, le32(id)...)
+		b = append(b, le32(n)...)
+		b = append(b, le32(day)...)
+		b = append(b, []byte(name + "      ")[:6]...)
+		return append(b, ok)
+	}
+	table := testADT(t, columns, [][]byte{
+		record(1, 7, 2440588, "ann", 'T'),
+		record(2, math.MinInt32, 0, "bob", 'F'),
+		record(3, -2, 2440589, "cy", 'T'),
+	})
+	r, err := table.ArrowReader(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	var ids, ns, days []int64
+	var names []string
+	var oks []bool
+	for r.Next() {
+		rec := r.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			ids = append(ids, int64(rec.Column(0).(*array.Uint32).Value(i)))
+			n := rec.Column(1).(*array.Int32)
+			if n.IsNull(i) {
+				ns = append(ns, -1)
+			} else {
+				ns = append(ns, int64(n.Value(i)))
+			}
+			born := rec.Column(2).(*array.Date32)
+			if born.IsNull(i) {
+				days = append(days, -1)
+			} else {
+				days = append(days, int64(born.Value(i)))
+			}
+			names = append(names, rec.Column(3).(*array.String).Value(i))
+			oks = append(oks, rec.Column(4).(*array.Boolean).Value(i))
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	check := func(name string, got, want interface{}) {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	check("ID", ids, []int64{1, 2, 3})
+	check("N", ns, []int64{7, -1, -2})
+	check("BORN", days, []int64{0, -1, 1})
+	check("NAME", names, []string{"ann", "bob", "cy"})
+	check("OK", oks, []bool{true, false, true})
+}
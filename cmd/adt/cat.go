@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+func catCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("cat", "[flags] file.ADT ...")
+	var tf tableFlags
+	var ff formatFlags
+	tf.register(fs)
+	ff.register(fs)
+	header := fs.Bool("header", true, "print a header row of column names")
+	return fs, func(fs *flag.FlagSet) error {
+		out, err := tf.stdout()
+		if err != nil {
+			return err
+		}
+		err = tf.each(fs, func(path string, table *adt.Table) error {
+			w, err := export.NewCSVWriter(out, table, export.CSVOptions{
+				Format: ff.format(),
+				Comma:  '\t',
+				Quote:  export.QuoteMinimal,
+				Header: *header,
+			})
+			if err != nil {
+				return err
+			}
+			if err := tf.records().Each(table, func(i int, r adt.Record) error {
+				return w.Write(r)
+			}); err != nil {
+				return err
+			}
+			return w.Close()
+		})
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+// recordWriter is implemented by each of the export package's writers.
+type recordWriter interface {
+	Write(adt.Record) error
+	Close() error
+}
+
+func exportCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("export", "-format=json|csv|sql|parquet [flags] file.ADT")
+	var tf tableFlags
+	var ff formatFlags
+	tf.register(fs)
+	ff.register(fs)
+	var (
+		format      = fs.String("format", "json", "output format (json, csv, sql, parquet)")
+		out         = fs.String("o", "-", "path to write to (- for stdout)")
+		array       = fs.Bool("array", false, "json: write a single array instead of newline delimited objects")
+		schema      = fs.Bool("schema", false, "json: write an object describing the columns first; with -array, wrap them as {\"schema\":...,\"records\":[...]}")
+		indent      = fs.Bool("indent", false, "json: indent each object")
+		delimiter   = fs.String("d", ",", "csv: field delimiter")
+		quote       = fs.String("quote", export.QuoteMinimal, "csv: quoting policy (minimal, all, nonnumeric, none)")
+		header      = fs.Bool("header", true, "csv: write a header row")
+		dialect     = fs.String("dialect", "mysql", "sql: dialect (mysql, postgres, sqlite, sqlserver)")
+		tableName   = fs.String("table", "", "sql: name of the target table (default the file name)")
+		batch       = fs.Int("batch", 100, "sql: rows per INSERT statement")
+		indexes     = fs.Bool("indexes", false, "sql: write index DDL after the rows")
+		rowGroup    = fs.Int64("rowgroup", export.DefaultRowGroupSize, "parquet: approximate row group size in bytes")
+		compression = fs.String("compression", "snappy", "parquet: compression codec (none, snappy, gzip, lz4, zstd)")
+	)
+	return fs, func(fs *flag.FlagSet) error {
+		paths, err := tf.paths(fs)
+		if err != nil {
+			return err
+		}
+		if len(paths) != 1 {
+			return errors.New("export takes a single ADT file")
+		}
+		table, err := tf.open(paths[0])
+		if err != nil {
+			return err
+		}
+		defer table.Close()
+
+		var dst io.Writer = os.Stdout
+		if *out != "-" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			dst = f
+		}
+		buf := bufio.NewWriter(dst)
+		// Parquet is binary; the other formats are text in -encoding.
+		var enc io.WriteCloser
+		if *format != "parquet" {
+			if enc, err = export.NewEncoder(buf, tf.encoding); err != nil {
+				return err
+			}
+		}
+
+		var w recordWriter
+		switch *format {
+		case "json":
+			opts := export.JSONOptions{Format: ff.format(), Array: *array, Schema: *schema}
+			if *indent {
+				opts.Indent = "  "
+			}
+			w, err = export.NewJSONWriter(enc, table, opts)
+		case "csv":
+			comma := []rune(*delimiter)
+			if len(comma) != 1 {
+				return fmt.Errorf("delimiter must be a single character, got %q", *delimiter)
+			}
+			w, err = export.NewCSVWriter(enc, table, export.CSVOptions{
+				Format: ff.format(),
+				Comma:  comma[0],
+				Quote:  *quote,
+				Header: *header,
+			})
+		case "sql":
+			d, derr := adt.LookupDialect(*dialect)
+			if derr != nil {
+				return derr
+			}
+			w, err = export.NewSQLWriter(enc, table, export.SQLOptions{
+				Dialect: d,
+				Table:   *tableName,
+				Batch:   *batch,
+				Indexes: *indexes,
+			})
+		case "parquet":
+			w, err = export.NewParquetWriter(buf, table, export.ParquetOptions{
+				RowGroupSize: *rowGroup,
+				Compression:  *compression,
+			})
+		default:
+			return fmt.Errorf("unknown format %q", *format)
+		}
+		if err != nil {
+			return err
+		}
+		if err := tf.records().Each(table, func(i int, r adt.Record) error {
+			return w.Write(r)
+		}); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if enc != nil {
+			if err := enc.Close(); err != nil {
+				return err
+			}
+		}
+		return buf.Flush()
+	}
+}
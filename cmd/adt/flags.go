@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+// tableFlags are the flags shared by the commands reading tables.
+type tableFlags struct {
+	file     string
+	start    int
+	count    int
+	columns  string
+	deleted  string
+	encoding string
+}
+
+func (f *tableFlags) register(fs *flag.FlagSet) {
+	f.registerPath(fs)
+	fs.IntVar(&f.start, "i", 0, "index of the first record")
+	fs.IntVar(&f.count, "n", -1, "number of records (-1 for all)")
+	fs.StringVar(&f.columns, "columns", "", "comma separated columns to read, in order (default all)")
+	fs.StringVar(&f.deleted, "deleted", export.DeletedInclude, "deleted record policy (include, skip, only)")
+}
+
+// registerPath registers just -f and -encoding, for commands that describe
+// tables without reading a selection of their records.
+func (f *tableFlags) registerPath(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "f", "", "path to ADT file (or pass files as arguments)")
+	fs.StringVar(&f.encoding, "encoding", export.EncodingUTF8, "text output encoding (utf-8, utf-8-bom, cp1252)")
+}
+
+// paths returns -f followed by the command's arguments.
+func (f *tableFlags) paths(fs *flag.FlagSet) ([]string, error) {
+	var paths []string
+	if f.file != "" {
+		paths = append(paths, f.file)
+	}
+	paths = append(paths, fs.Args()...)
+	if len(paths) == 0 {
+		return nil, errors.New("no ADT file given")
+	}
+	return paths, nil
+}
+
+// records returns the records selected by -i, -n and -deleted.
+func (f *tableFlags) records() export.Range {
+	return export.Range{Start: f.start, Count: f.count, Deleted: f.deleted}
+}
+
+// open opens the table at path, restricted to -columns.
+func (f *tableFlags) open(path string) (*adt.Table, error) {
+	table, err := adt.TableFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	if f.columns != "" {
+		columns = strings.Split(f.columns, ",")
+	}
+	view, err := export.Project(table, columns)
+	if err != nil {
+		table.Close()
+		return nil, err
+	}
+	return view, nil
+}
+
+// each opens every table given on the command line in turn.
+func (f *tableFlags) each(fs *flag.FlagSet, fn func(path string, table *adt.Table) error) error {
+	paths, err := f.paths(fs)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		table, err := f.open(path)
+		if err != nil {
+			return err
+		}
+		err = fn(path, table)
+		table.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stdout returns standard output in the -encoding encoding.
+func (f *tableFlags) stdout() (io.WriteCloser, error) {
+	return export.NewEncoder(os.Stdout, f.encoding)
+}
+
+// formatFlags control how values are rendered as text.
+type formatFlags struct {
+	null      string
+	date      string
+	timestamp string
+	time      string
+	binary    string
+}
+
+func (f *formatFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.null, "null", "", "text written for NULL values in text formats")
+	fs.StringVar(&f.date, "date", "", "Go time layout for Date columns (default ISO 8601)")
+	fs.StringVar(&f.timestamp, "timestamp", "", "Go time layout for Timestamp columns (default per format)")
+	fs.StringVar(&f.time, "time", "", "Go time layout for Time columns (default HH:MM:SS.mmm)")
+	fs.StringVar(&f.binary, "binary", "", "memo and blob rendering (text, base64, hex, omit)")
+}
+
+func (f *formatFlags) format() export.Format {
+	return export.Format{
+		Null:            f.null,
+		DateLayout:      f.date,
+		TimestampLayout: f.timestamp,
+		TimeLayout:      f.time,
+		Binary:          f.binary,
+	}
+}
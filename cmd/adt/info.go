@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tmc/adt"
+)
+
+func infoCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("info", "[flags] file.ADT ...")
+	var tf tableFlags
+	tf.registerPath(fs)
+	return fs, func(fs *flag.FlagSet) error {
+		out, err := tf.stdout()
+		if err != nil {
+			return err
+		}
+		err = tf.each(fs, func(path string, table *adt.Table) error {
+			deleted := 0
+			for i := 0; i < int(table.RecordCount); i++ {
+				d, err := table.IsDeleted(i)
+				if err != nil {
+					return err
+				}
+				if d {
+					deleted++
+				}
+			}
+			memo := "none"
+			ext := filepath.Ext(path)
+			if _, err := os.Stat(path[:len(path)-len(ext)] + ".ADM"); err == nil {
+				memo = path[:len(path)-len(ext)] + ".ADM"
+			}
+			fmt.Fprintf(out, "%s\n", table.Name)
+			fmt.Fprintf(out, "  records:       %d (%d deleted)\n", table.RecordCount, deleted)
+			fmt.Fprintf(out, "  record length: %d\n", table.RecordLength)
+			fmt.Fprintf(out, "  data offset:   %d\n", table.DataOffset)
+			fmt.Fprintf(out, "  columns:       %d\n", len(table.Columns))
+			_, err := fmt.Fprintf(out, "  memo file:     %s\n", memo)
+			return err
+		})
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+}
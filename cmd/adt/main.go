@@ -0,0 +1,90 @@
+// Command adt inspects, exports and serves ADT tables.
+//
+// Usage:
+//
+//	adt <command> [flags] [file.ADT ...]
+//
+// Run "adt help <command>" for the flags of a command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command is an adt subcommand.
+type command struct {
+	name  string
+	short string
+	// flags returns the command's flag set and the function running it
+	// once the flags are parsed.
+	flags func() (*flag.FlagSet, func(fs *flag.FlagSet) error)
+}
+
+var commands = []*command{
+	{"info", "print a summary of each table", infoCommand},
+	{"schema", "print the columns of each table", schemaCommand},
+	{"cat", "print records as tab separated text", catCommand},
+	{"export", "write a table as json, csv, sql or parquet", exportCommand},
+	{"verify", "check tables for structural damage", verifyCommand},
+	{"salvage", "recover the readable records of a damaged table", salvageCommand},
+	{"serve", "serve a directory of tables over HTTP", serveCommand},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: adt <command> [flags] [file.ADT ...]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.name, c.short)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"adt help <command>\" for the flags of a command.\n")
+}
+
+func lookup(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := os.Args[1], os.Args[2:]
+	if name == "help" || name == "-h" || name == "-help" {
+		if len(args) > 0 && lookup(args[0]) != nil {
+			fs, _ := lookup(args[0]).flags()
+			fs.Usage()
+			return
+		}
+		usage()
+		return
+	}
+	c := lookup(name)
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "adt: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+	fs, run := c.flags()
+	fs.Parse(args)
+	if err := run(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "adt %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet returns a flag set for the named command whose usage lists
+// its flags under synopsis.
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: adt %s %s\n\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
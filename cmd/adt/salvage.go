@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+func salvageCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("salvage", "[flags] file.ADT")
+	var ff formatFlags
+	ff.register(fs)
+	var (
+		file    = fs.String("f", "", "path to damaged ADT file")
+		out     = fs.String("o", "", "write the recovered records to this new ADT file, and its memo file alongside, instead of printing them")
+		partial = fs.Bool("partial", false, "keep a partial trailing record, zero filled, in the -o file")
+		deleted = fs.Bool("deleted", true, "keep deleted records")
+	)
+	return fs, func(fs *flag.FlagSet) error {
+		tf := tableFlags{file: *file}
+		paths, err := tf.paths(fs)
+		if err != nil {
+			return err
+		}
+		if len(paths) != 1 {
+			return errors.New("salvage takes one file")
+		}
+		table, err := adt.TableFromPath(paths[0])
+		if err != nil {
+			return err
+		}
+		defer table.Close()
+
+		var emit func(r *adt.SalvagedRecord) error
+		var sw *adt.SalvageWriter
+		if *out != "" {
+			f, err := os.OpenFile(*out, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if sw, err = adt.NewSalvageWriter(f, table); err != nil {
+				return err
+			}
+			emit = func(r *adt.SalvagedRecord) error {
+				if r.Partial && !*partial {
+					return nil
+				}
+				return sw.Write(r)
+			}
+		} else {
+			w := bufio.NewWriter(os.Stdout)
+			defer w.Flush()
+			lines, err := newSalvageLines(w, table, ff.format())
+			if err != nil {
+				return err
+			}
+			emit = lines.write
+		}
+
+		recovered, partials, damaged, fields := 0, 0, 0, 0
+		err = table.Salvage(func(r *adt.SalvagedRecord) error {
+			if r.Deleted && !*deleted {
+				return nil
+			}
+			recovered++
+			if r.Partial {
+				partials++
+			}
+			if !r.OK() {
+				damaged++
+				fields += len(r.Errors)
+			}
+			return emit(r)
+		})
+		if err != nil {
+			return err
+		}
+		if sw != nil {
+			if err := sw.Close(); err != nil {
+				return err
+			}
+			if err := copyMemo(table, *out); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s: header claims %d records, recovered %d (%d partial, %d with %d unreadable fields)\n",
+			table.Name, table.RecordCount, recovered, partials, damaged, fields)
+		if sw != nil {
+			fmt.Fprintf(os.Stderr, "wrote %d records to %s\n", sw.Records(), *out)
+		}
+		return nil
+	}
+}
+
+// copyMemo copies the memo file of table next to the salvaged table at
+// out, since the salvaged records keep their memo block numbers.
+func copyMemo(table *adt.Table, out string) error {
+	memos := false
+	for _, c := range table.Columns {
+		if c.Type == adt.ColumnTypeMemo {
+			memos = true
+		}
+	}
+	if !memos {
+		return nil
+	}
+	path := strings.TrimSuffix(out, filepath.Ext(out)) + ".ADM"
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	n, err := table.CopyMemo(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == adt.ErrNoMemoFile {
+		fmt.Fprintf(os.Stderr, "%s: no memo file to copy\n", table.Name)
+		return os.Remove(path)
+	}
+	if err != nil {
+		return fmt.Errorf("copying memo file: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "copied %d byte memo file to %s\n", n, path)
+	return nil
+}
+
+// salvageLines writes recovered records as JSON lines holding the record's
+// index, its flags, its values as written by an export.JSONWriter, and the
+// errors of the fields that failed, which read as null in the values.
+type salvageLines struct {
+	enc    *json.Encoder
+	values bytes.Buffer
+	jw     *export.JSONWriter
+}
+
+func newSalvageLines(w io.Writer, table *adt.Table, format export.Format) (*salvageLines, error) {
+	l := &salvageLines{enc: json.NewEncoder(w)}
+	var err error
+	l.jw, err = export.NewJSONWriter(&l.values, table, export.JSONOptions{Format: format})
+	return l, err
+}
+
+func (l *salvageLines) write(r *adt.SalvagedRecord) error {
+	l.values.Reset()
+	if err := l.jw.Write(r.Record); err != nil {
+		return err
+	}
+	if err := l.jw.Flush(); err != nil {
+		return err
+	}
+	errs := map[string]string{}
+	for name, err := range r.Errors {
+		errs[name] = err.Error()
+	}
+	return l.enc.Encode(struct {
+		Record  int               `json:"record"`
+		Deleted bool              `json:"deleted,omitempty"`
+		Partial bool              `json:"partial,omitempty"`
+		Values  json.RawMessage   `json:"values"`
+		Errors  map[string]string `json:"errors,omitempty"`
+	}{r.Index, r.Deleted, r.Partial, bytes.TrimSpace(l.values.Bytes()), errs})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+func schemaCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("schema", "[flags] file.ADT ...")
+	var tf tableFlags
+	tf.registerPath(fs)
+	format := fs.String("format", "text", "output format (text, json, sql)")
+	dialect := fs.String("dialect", "mysql", "SQL dialect with -format=sql (mysql, postgres, sqlite, sqlserver)")
+	return fs, func(fs *flag.FlagSet) error {
+		out, err := tf.stdout()
+		if err != nil {
+			return err
+		}
+		err = tf.each(fs, func(path string, table *adt.Table) error {
+			switch *format {
+			case "text":
+				fmt.Fprintln(out, table.Name)
+				w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+				fmt.Fprintln(w, "  NAME\tTYPE\tLENGTH\tDECIMALS\tNULLABLE")
+				for _, c := range table.Columns {
+					fmt.Fprintf(w, "  %s\t%s\t%d\t%d\t%v\n", c.Name, strings.TrimPrefix(c.Type.String(), "ColumnType"), c.Length, c.DecimalDigits, c.Nullable())
+				}
+				return w.Flush()
+			case "json":
+				return json.NewEncoder(out).Encode(export.NewJSONSchema(table, table.Columns))
+			case "sql":
+				d, err := adt.LookupDialect(*dialect)
+				if err != nil {
+					return err
+				}
+				ddl, err := table.DialectDDL(d, strings.TrimSuffix(table.Name, ".ADT"))
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintf(out, "%s;\n", ddl)
+				return err
+			}
+			return fmt.Errorf("unknown format %q", *format)
+		})
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/tmc/adt/server"
+)
+
+func serveCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("serve", "[flags]")
+	var (
+		path       = fs.String("path", ".", "path to ADT files")
+		verbose    = fs.Bool("v", false, "verbose")
+		addr       = fs.String("http", ":7001", "listen address")
+		config     = fs.String("conf", "", "path to foreign key config json")
+		publicKey  = fs.String("tlscrt", "", "path to tls certificate")
+		privateKey = fs.String("tlskey", "", "path to tls private key")
+		accessLog  = fs.String("access-log", "-", "file to append JSON access logs to, - for stderr, empty for none")
+		origins    = fs.String("origins", "", "comma separated origins allowed to read responses cross-origin, or *")
+		limits     = server.DefaultLimits
+		auth       server.AuthFiles
+	)
+	fs.DurationVar(&limits.ReadTimeout, "read-timeout", limits.ReadTimeout, "time allowed to read a request")
+	fs.DurationVar(&limits.WriteTimeout, "write-timeout", limits.WriteTimeout, "time allowed to write a response, renewed while exports stream")
+	fs.DurationVar(&limits.IdleTimeout, "idle-timeout", limits.IdleTimeout, "time an idle keep-alive connection is kept open")
+	fs.IntVar(&limits.MaxConcurrent, "max-concurrent", limits.MaxConcurrent, "requests served at once, 0 for no limit")
+	fs.Float64Var(&limits.Rate, "rate", limits.Rate, "requests per second allowed per client address, 0 for no limit")
+	fs.IntVar(&limits.Burst, "burst", limits.Burst, "requests a client may make at once above -rate")
+	fs.StringVar(&auth.Policy, "policy", "", "path to JSON policy mapping principals to tables and columns")
+	fs.StringVar(&auth.Tokens, "tokens", "", "path to bearer tokens, one \"principal token\" per line")
+	fs.StringVar(&auth.Htpasswd, "htpasswd", "", "path to basic auth users, one \"user:bcrypt-hash\" per line")
+	fs.StringVar(&auth.ClientCA, "clientca", "", "path to PEM CA certificates accepted for TLS client certificates")
+	return fs, func(fs *flag.FlagSet) error {
+		cfg, err := server.LoadConfig(*config)
+		if err != nil {
+			return err
+		}
+		srv := server.New(cfg, *path, *verbose)
+		srv.Limits = limits
+		if srv.AccessLog, err = server.OpenAccessLog(*accessLog); err != nil {
+			return err
+		}
+		if *origins != "" {
+			srv.Origins = strings.Split(*origins, ",")
+		}
+		if err := srv.LoadAuth(auth); err != nil {
+			return err
+		}
+		return srv.Serve(*addr, *publicKey, *privateKey)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tmc/adt"
+)
+
+// maxReported is the number of problems listed per table in text output.
+const maxReported = 10
+
+func verifyCommand() (*flag.FlagSet, func(*flag.FlagSet) error) {
+	fs := newFlagSet("verify", "[flags] file.ADT ...")
+	var (
+		file   = fs.String("f", "", "path to ADT file (or pass files as arguments)")
+		asJSON = fs.Bool("json", false, "print the reports as a JSON array")
+		all    = fs.Bool("all", false, "list every problem rather than the first 10 per table")
+	)
+	return fs, func(fs *flag.FlagSet) error {
+		tf := tableFlags{file: *file}
+		paths, err := tf.paths(fs)
+		if err != nil {
+			return err
+		}
+		// Verify sees the whole table, so -columns and friends don't apply.
+		reports := make([]*adt.VerifyReport, 0, len(paths))
+		for _, path := range paths {
+			reports = append(reports, verifyFile(path))
+		}
+
+		failed := 0
+		for _, r := range reports {
+			if !r.OK() {
+				failed++
+			}
+		}
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(reports); err != nil {
+				return err
+			}
+		} else {
+			for _, r := range reports {
+				printReport(r, *all)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d tables have problems", failed, len(reports))
+		}
+		return nil
+	}
+}
+
+// verifyFile verifies the table at path. A file that can't be opened or
+// whose header can't be parsed gets a report with that problem, so that
+// the other tables are still verified.
+func verifyFile(path string) *adt.VerifyReport {
+	table, err := adt.TableFromPath(path)
+	if err == nil {
+		var report *adt.VerifyReport
+		report, err = table.Verify()
+		table.Close()
+		if err == nil {
+			return report
+		}
+	}
+	report := &adt.VerifyReport{Table: filepath.Base(path), MemoSize: -1, Problems: []adt.Problem{}}
+	if fi, err := os.Stat(path); err == nil {
+		report.FileSize = fi.Size()
+	}
+	kind := adt.ProblemHeader
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		kind = adt.ProblemUnreadable
+	}
+	report.Problems = append(report.Problems, adt.Problem{Kind: kind, Record: -1, Message: err.Error()})
+	return report
+}
+
+func printReport(r *adt.VerifyReport, all bool) {
+	for i, p := range r.Problems {
+		if !all && i == maxReported {
+			fmt.Printf("%s: ... %d more\n", r.Table, len(r.Problems)-i)
+			break
+		}
+		fmt.Printf("%s: %s\n", r.Table, p)
+	}
+	more := ""
+	if r.Truncated {
+		more = "+"
+	}
+	fmt.Printf("%s: %d records, %d checked, %d deleted, %d%s problems\n",
+		r.Table, r.Records, r.Checked, r.Deleted, len(r.Problems), more)
+}
@@ -1,58 +1,86 @@
-// Command
+// Command adt2csv writes the records of an ADT table as CSV.
 package main
 
 import (
-	"encoding/csv"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
 )
 
 var (
-	flagFile  = flag.String("f", "", "path to ADT file")
-	flagIndex = flag.Int("i", 0, "starting index")
-	flagNum   = flag.Int("n", -1, "number of records")
+	flagFile      = flag.String("f", "", "path to ADT file")
+	flagIndex     = flag.Int("i", 0, "starting index")
+	flagNum       = flag.Int("n", -1, "number of records")
+	flagNull      = flag.String("null", "", "text written for NULL values")
+	flagDate      = flag.String("date", export.DefaultDateLayout, "Go time layout for Date columns")
+	flagTimestamp = flag.String("timestamp", export.DefaultTimestampLayout, "Go time layout for Timestamp columns")
+	flagTime      = flag.String("time", "", "Go time layout for Time columns (default HH:MM:SS.mmm)")
+	flagBinary    = flag.String("binary", export.BinaryText, "memo and blob rendering (text, base64, hex, omit)")
+	flagDelimiter = flag.String("d", ",", `field delimiter ("\t" for tabs)`)
+	flagQuote     = flag.String("quote", export.QuoteMinimal, "quoting policy (minimal, all, nonnumeric, none)")
+	flagHeader    = flag.Bool("header", true, "write a header row of column names")
+	flagColumns   = flag.String("columns", "", "comma separated columns to write, in order (default all)")
+	flagEncoding  = flag.String("encoding", export.EncodingUTF8, "output encoding (utf-8, utf-8-bom, cp1252)")
 )
 
 func main() {
 	flag.Parse()
-	table, err := adt.TableFromPath(*flagFile)
-	if err != nil {
+	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+}
+
+func run() error {
+	table, err := adt.TableFromPath(*flagFile)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
 	until := int(table.RecordCount)
-	if *flagNum != -1 {
+	if *flagNum != -1 && *flagIndex+*flagNum < until {
 		until = *flagIndex + *flagNum
 	}
 
-	w := csv.NewWriter(os.Stdout)
-	// header
-	headers := []string{}
-	for _, c := range table.Columns {
-		headers = append(headers, c.Name)
+	delimiter := strings.Replace(*flagDelimiter, `\t`, "\t", -1)
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) {
+		return fmt.Errorf("delimiter must be a single character, got %q", *flagDelimiter)
 	}
-	if err := w.Write(headers); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	var columns []string
+	if *flagColumns != "" {
+		columns = strings.Split(*flagColumns, ",")
+	}
+	w, err := export.NewCSVWriter(os.Stdout, table, export.CSVOptions{
+		Format: export.Format{
+			Null:            *flagNull,
+			DateLayout:      *flagDate,
+			TimestampLayout: *flagTimestamp,
+			TimeLayout:      *flagTime,
+			Binary:          *flagBinary,
+		},
+		Comma:    comma,
+		Quote:    *flagQuote,
+		Header:   *flagHeader,
+		Columns:  columns,
+		Encoding: *flagEncoding,
+	})
+	if err != nil {
+		return err
 	}
-
 	for i := *flagIndex; i < until; i++ {
-		fields := make([]string, 0, len(table.Columns))
 		r, err := table.Get(i)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-		for _, c := range table.Columns {
-			fields = append(fields, fmt.Sprint(r[c.Name]))
+			return err
 		}
-		if err := w.Write(fields); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		if err := w.Write(r); err != nil {
+			return err
 		}
 	}
-	w.Flush()
+	return w.Close()
 }
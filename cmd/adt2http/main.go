@@ -1,11 +1,12 @@
-// Command
+// Command adt2http serves a directory of ADT files over HTTP.
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"log"
-	"os"
+	"strings"
+
+	"github.com/tmc/adt/server"
 )
 
 var (
@@ -15,29 +16,51 @@ var (
 	flagConfig     = flag.String("conf", "", "path to config json")
 	flagPublicKey  = flag.String("tlscrt", "", "path to tls certificate")
 	flagPrivateKey = flag.String("tlskey", "", "path to tls private key")
+	flagPolicy     = flag.String("policy", "", "path to JSON policy mapping principals to tables and columns")
+	flagTokens     = flag.String("tokens", "", "path to bearer tokens, one \"principal token\" per line")
+	flagHtpasswd   = flag.String("htpasswd", "", "path to basic auth users, one \"user:bcrypt-hash\" per line")
+	flagClientCA   = flag.String("clientca", "", "path to PEM CA certificates accepted for TLS client certificates")
+	flagAccessLog  = flag.String("access-log", "-", "file to append JSON access logs to, - for stderr, empty for none")
+	flagOrigins    = flag.String("origins", "", "comma separated origins allowed to read responses cross-origin, or *")
+	limits         = server.DefaultLimits
 )
 
+func init() {
+	flag.DurationVar(&limits.ReadTimeout, "read-timeout", limits.ReadTimeout, "time allowed to read a request")
+	flag.DurationVar(&limits.WriteTimeout, "write-timeout", limits.WriteTimeout, "time allowed to write a response, renewed while exports stream")
+	flag.DurationVar(&limits.IdleTimeout, "idle-timeout", limits.IdleTimeout, "time an idle keep-alive connection is kept open")
+	flag.IntVar(&limits.MaxConcurrent, "max-concurrent", limits.MaxConcurrent, "requests served at once, 0 for no limit")
+	flag.Float64Var(&limits.Rate, "rate", limits.Rate, "requests per second allowed per client address, 0 for no limit")
+	flag.IntVar(&limits.Burst, "burst", limits.Burst, "requests a client may make at once above -rate")
+}
+
 func main() {
 	flag.Parse()
+	if *flagClientCA != "" && (*flagPublicKey == "" || *flagPrivateKey == "") {
+		log.Fatalln("-clientca needs -tlscrt and -tlskey")
+	}
 
-	cfg, err := loadConfig(*flagConfig)
+	cfg, err := server.LoadConfig(*flagConfig)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	srv := NewADTHTTPServer(cfg, *flagPath, *flagVerbose)
-	if err := srv.Serve(*flagAddr, *flagPublicKey, *flagPrivateKey); err != nil {
+	srv := server.New(cfg, *flagPath, *flagVerbose)
+	srv.Limits = limits
+	if srv.AccessLog, err = server.OpenAccessLog(*flagAccessLog); err != nil {
 		log.Fatalln(err)
 	}
-}
-
-func loadConfig(path string) (Config, error) {
-	if path == "" {
-		return nil, nil
+	if *flagOrigins != "" {
+		srv.Origins = strings.Split(*flagOrigins, ",")
 	}
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
+	if err := srv.LoadAuth(server.AuthFiles{
+		Policy:   *flagPolicy,
+		Tokens:   *flagTokens,
+		Htpasswd: *flagHtpasswd,
+		ClientCA: *flagClientCA,
+	}); err != nil {
+		log.Fatalln(err)
+	}
+	if err := srv.Serve(*flagAddr, *flagPublicKey, *flagPrivateKey); err != nil {
+		log.Fatalln(err)
 	}
-	result := Config{}
-	return result, json.NewDecoder(f).Decode(&result)
 }
@@ -1,47 +1,75 @@
-// Command
+// Command adt2json writes the records of an ADT table as JSON.
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
 )
 
 var (
-	flagFile   = flag.String("f", "", "path to ADT file")
-	flagIndex  = flag.Int("i", 0, "starting index")
-	flagNum    = flag.Int("n", -1, "number of records")
-	flagIndent = flag.Bool("indent", false, "ident")
+	flagFile      = flag.String("f", "", "path to ADT file")
+	flagIndex     = flag.Int("i", 0, "starting index")
+	flagNum       = flag.Int("n", -1, "number of records")
+	flagIndent    = flag.Bool("indent", false, "indent each object")
+	flagArray     = flag.Bool("array", false, "write a single JSON array instead of newline delimited objects")
+	flagSchema    = flag.Bool("schema", false, "write an object describing the columns before the records; with -array, wrap them as {\"schema\":...,\"records\":[...]}")
+	flagColumns   = flag.String("columns", "", "comma separated columns to write, in order (default all)")
+	flagBinary    = flag.String("binary", "", "memo and blob rendering (text, base64, hex, omit; default text memos and base64 blobs)")
+	flagDate      = flag.String("date", export.JSONDateLayout, "Go time layout for Date columns")
+	flagTimestamp = flag.String("timestamp", export.JSONTimestampLayout, "Go time layout for Timestamp columns")
 )
 
 func main() {
 	flag.Parse()
-	table, err := adt.TableFromPath(*flagFile)
-	if err != nil {
+	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+}
+
+func run() error {
+	table, err := adt.TableFromPath(*flagFile)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
 	until := int(table.RecordCount)
-	if *flagNum != -1 {
+	if *flagNum != -1 && *flagIndex+*flagNum < until {
 		until = *flagIndex + *flagNum
 	}
+
+	opts := export.JSONOptions{
+		Format: export.Format{
+			DateLayout:      *flagDate,
+			TimestampLayout: *flagTimestamp,
+			Binary:          *flagBinary,
+		},
+		Array:  *flagArray,
+		Schema: *flagSchema,
+	}
+	if *flagIndent {
+		opts.Indent = "  "
+	}
+	if *flagColumns != "" {
+		opts.Columns = strings.Split(*flagColumns, ",")
+	}
+	w, err := export.NewJSONWriter(os.Stdout, table, opts)
+	if err != nil {
+		return err
+	}
 	for i := *flagIndex; i < until; i++ {
 		r, err := table.Get(i)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			return err
 		}
-		var buf []byte
-		if *flagIndent {
-			buf, _ = json.MarshalIndent(r, "", "  ")
-		} else {
-			buf, _ = json.Marshal(r)
+		if err := w.Write(r); err != nil {
+			return err
 		}
-		os.Stdout.Write(buf)
-		os.Stdout.WriteString("\n")
 	}
-
+	return w.Close()
 }
@@ -0,0 +1,63 @@
+// Command adt2parquet converts an ADT table to an Apache Parquet file.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+var (
+	flagFile        = flag.String("f", "", "path to ADT file")
+	flagOut         = flag.String("o", "-", "path to write the Parquet file to (- for stdout)")
+	flagRowGroup    = flag.Int64("rowgroup", export.DefaultRowGroupSize, "approximate row group size in bytes")
+	flagPage        = flag.Int64("page", 0, "approximate data page size in bytes (0 uses the writer default)")
+	flagCompression = flag.String("compression", "snappy", "compression codec (none, snappy, gzip, lz4, zstd)")
+	flagDeleted     = flag.Bool("deleted", true, "include records flagged as deleted")
+)
+
+func main() {
+	flag.Parse()
+	if err := convert(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func convert() error {
+	table, err := adt.TableFromPath(*flagFile)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
+
+	var out io.Writer = os.Stdout
+	if *flagOut != "-" {
+		f, err := os.Create(*flagOut)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	n, err := export.WriteParquet(w, table, export.ParquetOptions{
+		RowGroupSize: *flagRowGroup,
+		PageSize:     *flagPage,
+		Compression:  *flagCompression,
+		SkipDeleted:  !*flagDeleted,
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d rows\n", n)
+	return nil
+}
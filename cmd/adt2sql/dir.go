@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/server"
+)
+
+// tableResult records the outcome of migrating a single table.
+type tableResult struct {
+	File    string  `json:"file"`
+	Table   string  `json:"table"`
+	Rows    int     `json:"rows"`
+	Skipped string  `json:"skipped,omitempty"`
+	Error   string  `json:"error,omitempty"`
+	Seconds float64 `json:"seconds"`
+}
+
+// fkResult records the outcome of creating a foreign key.
+type fkResult struct {
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	References string `json:"references"`
+	Error      string `json:"error,omitempty"`
+}
+
+// report summarizes a directory migration.
+type report struct {
+	Tables      []*tableResult `json:"tables"`
+	ForeignKeys []*fkResult    `json:"foreign_keys,omitempty"`
+	Rows        int            `json:"rows"`
+	Migrated    int            `json:"migrated"`
+	Skipped     int            `json:"skipped"`
+	Failed      int            `json:"failed"`
+}
+
+// tablePaths returns the ADT files in dir, sorted. dir may also name a data
+// dictionary (.ADD); the dictionary itself isn't decoded, so its tables are
+// taken to be the ADT files next to it.
+func tablePaths(dir string) ([]string, error) {
+	dir = tableDir(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".ADT") {
+			result = append(result, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// tableDir returns the directory holding the tables of -dir, which is the
+// dictionary's directory if it names a data dictionary.
+func tableDir(dir string) string {
+	if strings.EqualFold(filepath.Ext(dir), ".ADD") {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// migrateDir migrates (or, given a sync state, syncs) every table in dir
+// using -workers concurrent workers, then creates the foreign keys from
+// -conf and writes a report.
+func migrateDir(db *sqlx.DB, dialect adt.Dialect, dir string, state *syncState) error {
+	cfg, err := server.LoadConfig(*flagConfig)
+	if err != nil {
+		return err
+	}
+	paths, err := tablePaths(dir)
+	if err != nil {
+		return err
+	}
+	dir = tableDir(dir)
+	results := make([]*tableResult, len(paths))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	workers := *flagWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = migrateFile(paths[i], func(table *adt.Table, path, tableName string) (int, error) {
+					if state != nil {
+						return syncTable(db, dialect, table, path, tableName, state)
+					}
+					return loadTable(db, dialect, table, tableName)
+				})
+			}
+		}()
+	}
+	for i := range paths {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	rep, loaded := newReport(results)
+	rep.ForeignKeys = createForeignKeys(cfg, loaded, func(tableName, column string, ref *tableResult) error {
+		return createForeignKey(db, dialect, dir, tableName, column, ref)
+	})
+	return finishReport(rep)
+}
+
+// newReport summarizes results, also returning the tables that were
+// loaded keyed by upper case file name.
+func newReport(results []*tableResult) (*report, map[string]*tableResult) {
+	rep := &report{Tables: results}
+	loaded := make(map[string]*tableResult)
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			rep.Failed++
+		case r.Skipped != "":
+			rep.Skipped++
+		default:
+			rep.Migrated++
+			loaded[strings.ToUpper(r.File)] = r
+		}
+		rep.Rows += r.Rows
+	}
+	return rep, loaded
+}
+
+// finishReport logs and writes rep, failing if any table failed.
+func finishReport(rep *report) error {
+	log.Printf("%d tables migrated, %d skipped, %d failed, %d rows", rep.Migrated, rep.Skipped, rep.Failed, rep.Rows)
+	if err := writeReport(rep, *flagReport); err != nil {
+		return err
+	}
+	if rep.Failed > 0 {
+		return fmt.Errorf("%d tables failed", rep.Failed)
+	}
+	return nil
+}
+
+// migrateFile opens the table at path and, unless it has too few records,
+// migrates it with load.
+func migrateFile(path string, load func(table *adt.Table, path, tableName string) (int, error)) *tableResult {
+	start := time.Now()
+	base := filepath.Base(path)
+	result := &tableResult{
+		File:  base,
+		Table: strings.TrimSuffix(base, filepath.Ext(base)),
+	}
+	defer func() {
+		result.Seconds = time.Since(start).Seconds()
+	}()
+	table, err := adt.TableFromPath(path)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println(result.Table, err)
+		return result
+	}
+	defer table.Close()
+	if int(table.RecordCount) < *flagMinRecords {
+		result.Skipped = fmt.Sprintf("too few records (%d)", table.RecordCount)
+		return result
+	}
+	result.Rows, err = load(table, path, result.Table)
+	if err != nil {
+		result.Error = err.Error()
+		log.Println(result.Table, err)
+	}
+	return result
+}
+
+// createForeignKeys adds the foreign keys in cfg between loaded tables by
+// calling create for each.
+func createForeignKeys(cfg server.Config, loaded map[string]*tableResult, create func(tableName, column string, ref *tableResult) error) []*fkResult {
+	var results []*fkResult
+	for file, fks := range cfg {
+		from, ok := loaded[strings.ToUpper(string(file))]
+		if !ok {
+			continue
+		}
+		for column, refFile := range fks {
+			to, ok := loaded[strings.ToUpper(string(refFile))]
+			if !ok {
+				continue
+			}
+			result := &fkResult{Table: from.Table, Column: string(column), References: to.Table}
+			if err := create(from.Table, string(column), to); err != nil {
+				result.Error = err.Error()
+				log.Println(from.Table, column, err)
+			}
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Table != results[j].Table {
+			return results[i].Table < results[j].Table
+		}
+		return results[i].Column < results[j].Column
+	})
+	return results
+}
+
+func createForeignKey(db *sqlx.DB, dialect adt.Dialect, dir, tableName, column string, ref *tableResult) error {
+	stmt, err := foreignKeySQL(dialect, dir, tableName, column, ref)
+	if err != nil {
+		return err
+	}
+	if *flagVerbose {
+		fmt.Println(stmt)
+	}
+	_, err = db.Exec(stmt)
+	return err
+}
+
+// foreignKeySQL returns the statement adding a foreign key from column of
+// tableName to the primary key of ref. SQLite can't add foreign keys to
+// existing tables, so there the column is indexed instead.
+func foreignKeySQL(dialect adt.Dialect, dir, tableName, column string, ref *tableResult) (string, error) {
+	if dialect == adt.SQLite {
+		return dialect.CreateIndex("idx_"+tableName+"_"+column, tableName) + " (" + dialect.QuoteIdent(column) + ")", nil
+	}
+	refTable, err := adt.TableFromPath(filepath.Join(dir, ref.File))
+	if err != nil {
+		return "", err
+	}
+	defer refTable.Close()
+	pk, err := refTable.GetPK()
+	if err != nil {
+		return "", err
+	}
+	if pk == nil {
+		return "", fmt.Errorf("%s has no primary key", ref.Table)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		dialect.QuoteIdent(tableName), dialect.QuoteIdent("fk_"+tableName+"_"+column),
+		dialect.QuoteIdent(column), dialect.QuoteIdent(ref.Table), dialect.QuoteIdent(pk.Name)), nil
+}
+
+func writeReport(rep *report, path string) error {
+	if path == "" {
+		return nil
+	}
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTablePaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"PEOPLE.ADT", "orders.adt", "PEOPLE.ADM", "DATA.ADD"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{filepath.Join(dir, "PEOPLE.ADT"), filepath.Join(dir, "orders.adt")}
+	for _, path := range []string{dir, filepath.Join(dir, "DATA.ADD")} {
+		got, err := tablePaths(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("tablePaths(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
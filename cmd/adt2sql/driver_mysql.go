@@ -1,5 +1,94 @@
-// +build: !skip_json_source
+//go:build !nomysql
+// +build !nomysql
 
 package main
 
-import _ "github.com/go-sql-driver/mysql"
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+)
+
+func init() {
+	bulkLoaders["mysql"] = loadData
+}
+
+// loadData streams table to MySQL with LOAD DATA LOCAL INFILE, one
+// statement per -commit records.
+func loadData(db *sqlx.DB, d adt.Dialect, table *adt.Table, tableName string, p *progress) error {
+	return chunks(table, func(start, end int) error {
+		name := fmt.Sprintf("adt2sql-%s-%d", tableName, start)
+		r, w := io.Pipe()
+		mysql.RegisterReaderHandler(name, func() io.Reader { return r })
+		defer mysql.DeregisterReaderHandler(name)
+
+		errc := make(chan error, 1)
+		go func() {
+			err := writeLoadData(w, table, start, end)
+			w.CloseWithError(err)
+			errc <- err
+		}()
+		_, err := db.Exec("LOAD DATA LOCAL INFILE 'Reader::" + name + "' INTO TABLE " + d.QuoteIdent(tableName) +
+			" CHARACTER SET utf8mb4 (" + quotedColumns(d, table) + ")")
+		r.Close()
+		if werr := <-errc; werr != nil && werr != io.ErrClosedPipe {
+			return werr
+		}
+		if err != nil {
+			return err
+		}
+		p.add(end - start)
+		return nil
+	})
+}
+
+// writeLoadData writes records [start, end) in LOAD DATA's default
+// tab-separated format.
+func writeLoadData(w io.Writer, table *adt.Table, start, end int) error {
+	for i := start; i < end; i++ {
+		r, err := table.Get(i)
+		if err != nil {
+			return err
+		}
+		fields := make([]string, 0, len(table.Columns))
+		for j, value := range sqlValues(adt.MySQL, table, r) {
+			fields = append(fields, loadDataField(table.Columns[j], value))
+		}
+		if _, err := io.WriteString(w, strings.Join(fields, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var loadDataEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`, "\x00", `\0`)
+
+func loadDataField(column *adt.Column, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return `\N`
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if column.Type == adt.ColumnTypeDate {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04:05.000")
+	case []byte:
+		return loadDataEscaper.Replace(string(v))
+	case string:
+		return loadDataEscaper.Replace(v)
+	}
+	return fmt.Sprint(value)
+}
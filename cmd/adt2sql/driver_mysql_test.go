@@ -0,0 +1,47 @@
+//go:build !nomysql
+// +build !nomysql
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/tmc/adt"
+)
+
+func TestWriteLoadData(t *testing.T) {
+	table := writePeople(t, t.TempDir(), 4)
+	var buf bytes.Buffer
+	if err := writeLoadData(&buf, table, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if want := "2\tp2\t22\n3\tp3\t\\N\n"; buf.String() != want {
+		t.Errorf("records 1-2:\ngot  %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestLoadDataField(t *testing.T) {
+	char := &adt.Column{Type: adt.ColumnTypeCharacter}
+	date := &adt.Column{Type: adt.ColumnTypeDate}
+	stamp := &adt.Column{Type: adt.ColumnTypeTimestamp}
+	at := time.Date(2024, 5, 6, 7, 8, 9, 10e6, time.UTC)
+	for _, tt := range []struct {
+		column *adt.Column
+		value  interface{}
+		want   string
+	}{
+		{char, nil, `\N`},
+		{char, "a\tb\nc\\d\x00", `a\tb\nc\\d\0`},
+		{char, []byte("x\ry"), `x\ry`},
+		{char, true, "1"},
+		{char, 0.25, "0.25"},
+		{date, at, "2024-05-06"},
+		{stamp, at, "2024-05-06 07:08:09.010"},
+	} {
+		if got := loadDataField(tt.column, tt.value); got != tt.want {
+			t.Errorf("loadDataField(%v, %q) = %q, want %q", tt.column.Type, tt.value, got, tt.want)
+		}
+	}
+}
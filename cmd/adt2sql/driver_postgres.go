@@ -0,0 +1,6 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package main
+
+import _ "github.com/lib/pq"
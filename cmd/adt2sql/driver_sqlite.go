@@ -0,0 +1,10 @@
+//go:build !nosqlite
+// +build !nosqlite
+
+package main
+
+import _ "modernc.org/sqlite"
+
+func init() {
+	sqliteDriver = true
+}
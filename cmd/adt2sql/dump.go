@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+	"github.com/tmc/adt/server"
+)
+
+// dump writes the DDL and data of -f or -dir as a SQL script to -dump
+// instead of loading it into a database.
+func dump() error {
+	dialect, err := adt.LookupDialect(*flagDialect)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	if *flagDump != "-" {
+		f, err := os.Create(*flagDump)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	if *flagDir != "" {
+		return dumpDir(w, dialect, *flagDir)
+	}
+	if *flagTableName == "" {
+		*flagTableName = strings.TrimSuffix(*flagFile, ".ADT")
+	}
+	table, err := adt.TableFromPath(*flagFile)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
+	if int(table.RecordCount) < *flagMinRecords {
+		return fmt.Errorf("too few records (%d)", table.RecordCount)
+	}
+	for _, columns := range flagUnique {
+		table.Indexes = append(table.Indexes, &adt.Index{Columns: columns, Unique: true})
+	}
+	for _, columns := range flagIndex {
+		table.Indexes = append(table.Indexes, &adt.Index{Columns: columns})
+	}
+	if _, err := dumpTable(w, dialect, table, *flagTableName); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// dumpDir dumps every table in dir, one at a time, followed by the foreign
+// keys from -conf.
+func dumpDir(w *bufio.Writer, dialect adt.Dialect, dir string) error {
+	cfg, err := server.LoadConfig(*flagConfig)
+	if err != nil {
+		return err
+	}
+	paths, err := tablePaths(dir)
+	if err != nil {
+		return err
+	}
+	dir = tableDir(dir)
+	var results []*tableResult
+	for _, path := range paths {
+		results = append(results, migrateFile(path, func(table *adt.Table, path, tableName string) (int, error) {
+			return dumpTable(w, dialect, table, tableName)
+		}))
+	}
+	rep, loaded := newReport(results)
+	rep.ForeignKeys = createForeignKeys(cfg, loaded, func(tableName, column string, ref *tableResult) error {
+		stmt, err := foreignKeySQL(dialect, dir, tableName, column, ref)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s;\n", stmt)
+		return err
+	})
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return finishReport(rep)
+}
+
+// dumpTable writes the DDL for table followed by INSERT statements of
+// -batch rows each, inside a transaction, then its index DDL.
+func dumpTable(w *bufio.Writer, dialect adt.Dialect, table *adt.Table, tableName string) (int, error) {
+	sw, err := export.NewSQLWriter(w, table, export.SQLOptions{
+		Dialect: dialect,
+		Table:   tableName,
+		Batch:   *flagBatch,
+		Indexes: *flagIndexes,
+	})
+	if err != nil {
+		return 0, err
+	}
+	p := newProgress(tableName, int(table.RecordCount), *flagProgress)
+	err = export.All.Each(table, func(i int, r adt.Record) error {
+		if err := sw.Write(r); err != nil {
+			return err
+		}
+		p.add(1)
+		return nil
+	})
+	if err != nil {
+		return p.done, err
+	}
+	if err := sw.Close(); err != nil {
+		return p.done, err
+	}
+	p.finish()
+	return p.done, nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+)
+
+// maxParams holds the largest number of bind parameters accepted in a
+// single statement, keyed by dialect name. SQL Server's limit of 2100 is
+// left some room for parameters its driver adds.
+var maxParams = map[string]int{
+	"mysql":     65535,
+	"postgres":  65535,
+	"sqlite":    32766,
+	"sqlserver": 2000,
+}
+
+// A bulkLoader loads every record of table into tableName using a
+// database-specific bulk path.
+type bulkLoader func(db *sqlx.DB, d adt.Dialect, table *adt.Table, tableName string, p *progress) error
+
+// bulkLoaders holds the available bulk loaders keyed by dialect name.
+var bulkLoaders = map[string]bulkLoader{
+	"postgres": copyFrom,
+}
+
+// insertRows loads every record of table with multi-row INSERT statements,
+// committing every -commit rows.
+func insertRows(db *sqlx.DB, d adt.Dialect, table *adt.Table, tableName string, p *progress) error {
+	scan := func(emit func(adt.Record) error) error {
+		for i := 0; i < int(table.RecordCount); i++ {
+			r, err := table.Get(i)
+			if err != nil {
+				return err
+			}
+			if err := emit(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return execBatches(db, d, table, tableName, scan, func(rows int) (string, error) {
+		return table.BatchInsertSQL(d, tableName, rows), nil
+	}, p)
+}
+
+// execBatches executes the statements returned by query, each with the
+// values of up to -batch of the records passed to emit by scan, committing
+// every -commit records. Each transaction allows explicit AutoIncrement
+// values in tableName while it runs.
+func execBatches(db *sqlx.DB, d adt.Dialect, table *adt.Table, tableName string, scan func(emit func(adt.Record) error) error, query func(rows int) (string, error), p *progress) (err error) {
+	if len(table.Columns) == 0 {
+		return fmt.Errorf("no columns to load")
+	}
+	batchSize := *flagBatch
+	if limit, ok := maxParams[d.Name()]; ok && batchSize > limit/len(table.Columns) {
+		batchSize = limit / len(table.Columns)
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	batchSQL, err := query(batchSize)
+	if err != nil {
+		return err
+	}
+	var (
+		tx      *sqlx.Tx
+		stmt    *sqlx.Stmt
+		args    []interface{}
+		pending int
+		first   int
+	)
+	defer func() {
+		if err != nil && tx != nil {
+			tx.Rollback()
+		}
+	}()
+	identityInsert := func(on bool) error {
+		if stmt := table.IdentityInsertSQL(d, tableName, on); stmt != "" {
+			_, err := tx.Exec(stmt)
+			return err
+		}
+		return nil
+	}
+	flush := func() error {
+		rows := len(args) / len(table.Columns)
+		if rows == 0 {
+			return nil
+		}
+		var err error
+		if rows == batchSize {
+			_, err = stmt.Exec(args...)
+		} else {
+			var partial string
+			if partial, err = query(rows); err == nil {
+				_, err = tx.Exec(partial, args...)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("writing rows %d-%d: %v", first, first+rows-1, err)
+		}
+		args = args[:0]
+		first += rows
+		p.add(rows)
+		return nil
+	}
+	emit := func(r adt.Record) error {
+		if tx == nil {
+			var err error
+			if tx, err = db.Beginx(); err != nil {
+				return err
+			}
+			if err := identityInsert(true); err != nil {
+				return err
+			}
+			if stmt, err = tx.Preparex(batchSQL); err != nil {
+				return err
+			}
+		}
+		args = append(args, sqlValues(d, table, r)...)
+		pending++
+		if len(args) == batchSize*len(table.Columns) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if *flagCommit > 0 && pending >= *flagCommit {
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := identityInsert(false); err != nil {
+				return err
+			}
+			if err := tx.Commit(); err != nil {
+				return err
+			}
+			tx, pending = nil, 0
+		}
+		return nil
+	}
+	if err = scan(emit); err != nil {
+		return err
+	}
+	if tx == nil {
+		return nil
+	}
+	if err = flush(); err != nil {
+		return err
+	}
+	if err = identityInsert(false); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// resetSequences moves the AutoIncrement generators of tableName past the
+// keys loaded from table.
+func resetSequences(db *sqlx.DB, d adt.Dialect, table *adt.Table, tableName string) error {
+	for _, stmt := range table.ResetSequenceSQL(d, tableName) {
+		if *flagVerbose {
+			fmt.Println(stmt)
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunks calls fn for consecutive record ranges of at most -commit records.
+func chunks(table *adt.Table, fn func(start, end int) error) error {
+	count := int(table.RecordCount)
+	size := *flagCommit
+	if size <= 0 {
+		size = count
+	}
+	for start := 0; start < count; start += size {
+		end := start + size
+		if end > count {
+			end = count
+		}
+		if err := fn(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFrom loads table with PostgreSQL's COPY FROM STDIN, one transaction
+// per -commit records.
+func copyFrom(db *sqlx.DB, d adt.Dialect, table *adt.Table, tableName string, p *progress) error {
+	query := "COPY " + d.QuoteIdent(tableName) + " (" + quotedColumns(d, table) + ") FROM STDIN"
+	return chunks(table, func(start, end int) error {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		for i := start; i < end; i++ {
+			r, err := table.Get(i)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.Exec(sqlValues(d, table, r)...); err != nil {
+				return fmt.Errorf("copying record %d: %v", i, err)
+			}
+		}
+		if _, err := stmt.Exec(); err != nil {
+			return err
+		}
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+		p.add(end - start)
+		return tx.Commit()
+	})
+}
+
+func quotedColumns(d adt.Dialect, table *adt.Table) string {
+	result := ""
+	for i, c := range table.Columns {
+		if i > 0 {
+			result += ","
+		}
+		result += d.QuoteIdent(c.Name)
+	}
+	return result
+}
+
+// progress periodically logs the number of rows loaded and the load rate.
+type progress struct {
+	name     string
+	total    int
+	done     int
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+}
+
+func newProgress(name string, total int, interval time.Duration) *progress {
+	now := time.Now()
+	return &progress{name: name, total: total, interval: interval, start: now, last: now}
+}
+
+func (p *progress) add(n int) {
+	p.done += n
+	if p.interval <= 0 || time.Since(p.last) < p.interval {
+		return
+	}
+	p.last = time.Now()
+	log.Printf("%s %d/%d rows (%.0f rows/sec)", p.name, p.done, p.total, p.rate())
+}
+
+func (p *progress) rate() float64 {
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed == 0 {
+		return 0
+	}
+	return float64(p.done) / elapsed
+}
+
+func (p *progress) finish() {
+	log.Printf("%s %d rows inserted in %v (%.0f rows/sec)", p.name, p.done, time.Since(p.start), p.rate())
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+)
+
+// recorded logs the calls made to the "record" driver: "begin", "commit",
+// "rollback", "prepare" and the statement's first word, and "exec" and the
+// number of arguments.
+var recorded []string
+
+func init() {
+	sql.Register("record", recordDriver{})
+}
+
+type recordDriver struct{}
+
+func (recordDriver) Open(string) (driver.Conn, error) { return recordConn{}, nil }
+
+type recordConn struct{}
+
+func (recordConn) Prepare(query string) (driver.Stmt, error) {
+	recorded = append(recorded, "prepare "+strings.Fields(query)[0])
+	return recordStmt{}, nil
+}
+
+func (recordConn) Close() error { return nil }
+
+func (recordConn) Begin() (driver.Tx, error) {
+	recorded = append(recorded, "begin")
+	return recordTx{}, nil
+}
+
+type recordTx struct{}
+
+func (recordTx) Commit() error {
+	recorded = append(recorded, "commit")
+	return nil
+}
+
+func (recordTx) Rollback() error {
+	recorded = append(recorded, "rollback")
+	return nil
+}
+
+type recordStmt struct{}
+
+func (recordStmt) Close() error  { return nil }
+func (recordStmt) NumInput() int { return -1 }
+
+func (recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	recorded = append(recorded, fmt.Sprint("exec ", len(args)))
+	return driver.RowsAffected(0), nil
+}
+
+func (recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+// record returns a database logging its calls to recorded, which it
+// clears, and sets -batch and -commit until the test ends.
+func record(t *testing.T, batch, commit int) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("record", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldBatch, oldCommit, oldProgress := *flagBatch, *flagCommit, *flagProgress
+	t.Cleanup(func() {
+		db.Close()
+		*flagBatch, *flagCommit, *flagProgress = oldBatch, oldCommit, oldProgress
+	})
+	recorded = nil
+	*flagBatch, *flagCommit, *flagProgress = batch, commit, 0
+	return db
+}
+
+func TestInsertRowsBatches(t *testing.T) {
+	table := writePeople(t, t.TempDir(), 25)
+	tests := []struct {
+		dialect       adt.Dialect
+		batch, commit int
+		want          []string
+	}{
+		// PEOPLE has 3 columns, so each row is 3 arguments.
+		{adt.PostgreSQL, 10, 0, []string{
+			"begin", "prepare INSERT", "exec 30", "exec 30", "prepare INSERT", "exec 15", "commit"}},
+		{adt.PostgreSQL, 10, 12, []string{
+			"begin", "prepare INSERT", "exec 30", "prepare INSERT", "exec 6", "commit",
+			"begin", "prepare INSERT", "exec 30", "prepare INSERT", "exec 6", "commit",
+			"begin", "prepare INSERT", "prepare INSERT", "exec 3", "commit"}},
+		// Batches are cut down to the dialect's parameter limit.
+		{adt.SQLServer, 1000, 0, []string{
+			"begin", "prepare SET", "exec 0", "prepare INSERT", "prepare INSERT", "exec 75", "prepare SET", "exec 0", "commit"}},
+	}
+	for _, tt := range tests {
+		db := record(t, tt.batch, tt.commit)
+		if err := insertRows(db, tt.dialect, table, "PEOPLE", newProgress("PEOPLE", 25, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(recorded, tt.want) {
+			t.Errorf("%s -batch %d -commit %d:\ngot  %q\nwant %q", tt.dialect.Name(), tt.batch, tt.commit, recorded, tt.want)
+		}
+	}
+}
+
+func TestBatchSizeLimit(t *testing.T) {
+	table := writePeople(t, t.TempDir(), 1)
+	for _, tt := range []struct {
+		dialect adt.Dialect
+		want    int
+	}{
+		{adt.PostgreSQL, 65535 / 3},
+		{adt.SQLite, 32766 / 3},
+		{adt.SQLServer, 2000 / 3},
+	} {
+		db := record(t, 100000, 0)
+		var rows []int
+		err := execBatches(db, tt.dialect, table, "PEOPLE", func(func(adt.Record) error) error { return nil },
+			func(n int) (string, error) {
+				rows = append(rows, n)
+				return "", nil
+			}, newProgress("PEOPLE", 1, 0))
+		if err != nil || !reflect.DeepEqual(rows, []int{tt.want}) {
+			t.Errorf("%s: batches of %v rows, %v; want %d", tt.dialect.Name(), rows, err, tt.want)
+		}
+	}
+}
+
+func TestNoColumns(t *testing.T) {
+	table := writeADT(t, t.TempDir(), "EMPTY.ADT", nil, [][]byte{{}})
+	db := record(t, 10, 0)
+	if err := insertRows(db, adt.PostgreSQL, table, "EMPTY", newProgress("EMPTY", 1, 0)); err == nil {
+		t.Error("loading a table without columns: no error")
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	table := writePeople(t, t.TempDir(), 5)
+	db := record(t, 10, 2)
+	if err := copyFrom(db, adt.PostgreSQL, table, "PEOPLE", newProgress("PEOPLE", 5, 0)); err != nil {
+		t.Fatal(err)
+	}
+	// Each chunk of -commit records is copied in its own transaction, the
+	// COPY ending with an Exec without arguments.
+	chunk := func(n int) []string {
+		result := []string{"begin", "prepare COPY"}
+		for i := 0; i < n; i++ {
+			result = append(result, "exec 3")
+		}
+		return append(result, "exec 0", "commit")
+	}
+	want := append(append(chunk(2), chunk(2)...), chunk(1)...)
+	if !reflect.DeepEqual(recorded, want) {
+		t.Errorf("got  %q\nwant %q", recorded, want)
+	}
+}
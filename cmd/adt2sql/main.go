@@ -11,18 +11,50 @@ import (
 	"strings"
 	"time"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/jmoiron/sqlx"
 	"github.com/tmc/adt"
 )
 
 var (
 	flagFile       = flag.String("f", "", "path to ADT file")
+	flagDir        = flag.String("dir", "", "path to a directory of ADT files, or the data dictionary (.ADD) beside them, to migrate in full")
+	flagWorkers    = flag.Int("workers", 4, "number of tables migrated concurrently with -dir")
+	flagConfig     = flag.String("conf", "", "path to foreign key config json, created after all tables load with -dir")
+	flagReport     = flag.String("report", "", "path to write a JSON summary report to with -dir (- for stdout)")
 	flagTableName  = flag.String("n", "", "name of resulting database table")
 	flagVerbose    = flag.Bool("v", false, "verbose")
 	flagMinRecords = flag.Int("minrecords", 1, "if a table has fewer than this many records it will be skipped")
+	flagIndexes    = flag.Bool("indexes", false, "create indexes after loading")
+	flagBatch      = flag.Int("batch", 100, "number of rows per INSERT statement")
+	flagCommit     = flag.Int("commit", 10000, "number of rows per transaction (0 loads the table in a single transaction)")
+	flagBulk       = flag.Bool("bulk", false, "use the database's bulk loading path (COPY, LOAD DATA) when available")
+	flagProgress   = flag.Duration("progress", 10*time.Second, "interval between progress reports (0 disables them)")
+	flagSync       = flag.Bool("sync", false, "incrementally upsert changed records and remove deleted ones instead of loading every record")
+	flagState      = flag.String("state", "adt2sql_state.json", "path to the watermark state file used by -sync")
+	flagAlter      = flag.Bool("alter", false, "apply ALTER TABLE statements when an existing table's columns differ")
+	flagDrop       = flag.Bool("drop", false, "with -alter, also drop target columns no longer in the ADT table")
+	flagDump       = flag.String("dump", "", "write a SQL script to this path (- for stdout) instead of connecting to DATABASE_URL")
+	flagSQLite     = flag.String("sqlite", "", "write to this SQLite database file instead of DATABASE_URL (one file holds a whole -dir)")
+	flagDialect    = flag.String("dialect", "mysql", "SQL dialect of the -dump script (mysql, postgres, sqlite, sqlserver)")
+	flagIndex      columnLists
+	flagUnique     columnLists
 )
 
+func init() {
+	flag.Var(&flagIndex, "index", "comma separated columns to index with -f (may be repeated)")
+	flag.Var(&flagUnique, "unique", "comma separated columns that are unique with -f (may be repeated)")
+}
+
+// columnLists is a repeatable flag of comma separated column names.
+type columnLists [][]string
+
+func (c *columnLists) String() string { return fmt.Sprint(*c) }
+
+func (c *columnLists) Set(value string) error {
+	*c = append(*c, strings.Split(value, ","))
+	return nil
+}
+
 func main() {
 	flag.Parse()
 	if err := migrate(); err != nil {
@@ -32,14 +64,31 @@ func main() {
 }
 
 func migrate() error {
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		return fmt.Errorf("No value present in DATABASE_URL environment variable.")
+	// Index tags aren't read from .ADI files, so -index and -unique are the
+	// only source of indexes, and they name the columns of a single table.
+	if *flagDir != "" && len(flagIndex)+len(flagUnique) > 0 {
+		return fmt.Errorf("-index and -unique apply to the -f table and can't be used with -dir")
 	}
-	db, err := newDBFromURL(dbURL)
+	if *flagDump != "" {
+		return dump()
+	}
+	db, err := openDB()
 	if err != nil {
 		return err
 	}
+	dialect, err := adt.LookupDialect(db.DriverName())
+	if err != nil {
+		return err
+	}
+	var state *syncState
+	if *flagSync {
+		if state, err = loadState(*flagState); err != nil {
+			return err
+		}
+	}
+	if *flagDir != "" {
+		return migrateDir(db, dialect, *flagDir, state)
+	}
 	if *flagTableName == "" {
 		*flagTableName = strings.TrimSuffix(*flagFile, ".ADT")
 	}
@@ -51,50 +100,96 @@ func migrate() error {
 	if int(table.RecordCount) < *flagMinRecords {
 		return fmt.Errorf("too few records (%d)", table.RecordCount)
 	}
-
-	ddl, err := table.SQLDDL(*flagTableName)
-	if err != nil {
+	for _, columns := range flagUnique {
+		table.Indexes = append(table.Indexes, &adt.Index{Columns: columns, Unique: true})
+	}
+	for _, columns := range flagIndex {
+		table.Indexes = append(table.Indexes, &adt.Index{Columns: columns})
+	}
+	if state != nil {
+		_, err = syncTable(db, dialect, table, *flagFile, *flagTableName, state)
 		return err
 	}
+	_, err = loadTable(db, dialect, table, *flagTableName)
+	return err
+}
 
-	if *flagVerbose {
-		fmt.Println(ddl)
+// openDB opens the -sqlite file or, without it, the DATABASE_URL database.
+func openDB() (*sqlx.DB, error) {
+	if *flagSQLite != "" {
+		return openSQLite(*flagSQLite)
 	}
-	if _, err = db.Exec(ddl); err != nil {
-		return err
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return nil, fmt.Errorf("No value present in DATABASE_URL environment variable.")
 	}
+	return newDBFromURL(dbURL)
+}
 
-	prepped, err := db.Preparex(table.InsertSQL(*flagTableName))
+// loadTable creates tableName and loads every record of table into it,
+// returning the number of rows loaded.
+func loadTable(db *sqlx.DB, dialect adt.Dialect, table *adt.Table, tableName string) (int, error) {
+	err := createTable(db, dialect, table, tableName)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	for i := 0; i < int(table.RecordCount); i++ {
-		r, err := table.Get(i)
-		if err != nil {
-			return err
-		}
+	p := newProgress(tableName, int(table.RecordCount), *flagProgress)
+	if bulk, ok := bulkLoaders[dialect.Name()]; ok && *flagBulk {
+		err = bulk(db, dialect, table, tableName, p)
+	} else {
+		err = insertRows(db, dialect, table, tableName, p)
+	}
+	if err == nil {
+		err = resetSequences(db, dialect, table, tableName)
+	}
+	if err != nil {
+		return p.done, err
+	}
+	p.finish()
 
-		values := make([]interface{}, 0, len(table.Columns))
-		for _, column := range table.Columns {
-			var value interface{} = r[column.Name]
-			if !reflect.ValueOf(value).IsValid() {
-				value = nil
+	if *flagIndexes {
+		for _, stmt := range table.IndexDDL(dialect, tableName) {
+			if *flagVerbose {
+				fmt.Println(stmt)
 			}
-			if dur, ok := value.(time.Duration); ok {
-				value = durToSQL(dur)
+			if _, err = db.Exec(stmt); err != nil {
+				return p.done, err
 			}
-			values = append(values, value)
-		}
-		if _, err = prepped.Exec(values...); err != nil {
-			fmt.Println("insert error")
-			spew.Dump(r)
-			return err
 		}
 	}
-	log.Println(*flagTableName, table.RecordCount, "rows inserted")
 
-	return nil
+	return p.done, nil
+}
+
+// sqlValues returns the values of r in column order, converted to types
+// accepted by the database drivers. SQLite has no date or time types, so
+// those are stored as ISO-8601 text.
+func sqlValues(d adt.Dialect, table *adt.Table, r adt.Record) []interface{} {
+	values := make([]interface{}, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		var value interface{} = r[column.Name]
+		if !reflect.ValueOf(value).IsValid() {
+			value = nil
+		}
+		if d == adt.SQLite {
+			if t, ok := value.(time.Time); ok {
+				value = strings.Trim(d.Literal(column, t), "'")
+			}
+		}
+		if dur, ok := value.(time.Duration); ok {
+			value = adt.FormatDuration(dur)
+		}
+		if memo, ok := value.([]byte); ok && column.Type == adt.ColumnTypeMemo {
+			value = adt.DecodeString(memo)
+		}
+		// PostgreSQL text can't contain NUL.
+		if s, ok := value.(string); ok && d == adt.PostgreSQL {
+			value = strings.Replace(s, "\x00", "", -1)
+		}
+		values = append(values, value)
+	}
+	return values
 }
 
 func newDBFromURL(URL string) (*sqlx.DB, error) {
@@ -102,25 +197,37 @@ func newDBFromURL(URL string) (*sqlx.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	switch p.Scheme {
+	case "sqlite", "sqlite3":
+		return openSQLite(p.Host + p.Path)
+	case "postgres", "postgresql":
+		// lib/pq takes the URL itself as its connection string.
+		return sqlx.Connect("postgres", URL)
+	}
 
-	DSN := strings.TrimLeft(p.String(), p.Scheme+"://")
+	DSN := strings.TrimPrefix(URL, p.Scheme+"://")
 	return sqlx.Connect(p.Scheme, DSN)
 }
 
-func durToSQL(d time.Duration) string {
-	sign := 1
-	if d < 0 {
-		sign = -1
-		d = -d
-	}
-	ns := int(d % 1e9)
-	d /= 1e9
-	sec := int(d % 60)
-	d /= 60
-	min := int(d % 60)
-	hour := int(d/60) * sign
-	if ns == 0 {
-		return fmt.Sprintf("%d:%02d:%02d", hour, min, sec)
-	}
-	return fmt.Sprintf("%d:%02d:%02d.%04d", hour, min, sec, ns/1e6)
+// sqliteDriver is set when the SQLite driver is built in, which it is
+// unless built with -tags nosqlite.
+var sqliteDriver bool
+
+// openSQLite opens the SQLite database file at path, creating it if needed.
+// SQLite allows a single writer, so the pool is limited to one connection.
+func openSQLite(path string) (*sqlx.DB, error) {
+	if !sqliteDriver {
+		return nil, fmt.Errorf("SQLite support is not built in; rebuild without -tags nosqlite")
+	}
+	db, err := sqlx.Connect("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	// A failed conversion is simply rerun, so skip syncing to disk.
+	if _, err := db.Exec("PRAGMA synchronous = OFF"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
 }
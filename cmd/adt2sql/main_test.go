@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+)
+
+// writeADT writes a table file to dir holding records, each prefixed with
+// an active record header, and opens it.
+func writeADT(t *testing.T, dir, file string, columns []*adt.Column, records [][]byte) *adt.Table {
+	t.Helper()
+	recordLength := 5
+	for _, c := range columns {
+		recordLength += int(c.Length)
+	}
+	dataOffset := adt.HeaderLength + adt.ColumnDescriptorLength*len(columns)
+	buf := make([]byte, dataOffset)
+	copy(buf, adt.MagicHeader)
+	binary.LittleEndian.PutUint32(buf[24:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(buf[32:], uint16(dataOffset))
+	binary.LittleEndian.PutUint32(buf[36:], uint32(recordLength))
+	for i, c := range columns {
+		d := buf[adt.HeaderLength+adt.ColumnDescriptorLength*i:]
+		copy(d, c.Name)
+		d[129] = byte(c.Type)
+		binary.LittleEndian.PutUint16(d[131:], c.Offset)
+		binary.BigEndian.PutUint16(d[134:], c.Length)
+		binary.BigEndian.PutUint16(d[138:], c.DecimalDigits)
+	}
+	for _, r := range records {
+		rec := make([]byte, recordLength)
+		copy(rec, adt.RecordMagicHeader)
+		copy(rec[5:], r)
+		buf = append(buf, rec...)
+	}
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+	table, err := adt.TableFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { table.Close() })
+	return table
+}
+
+func le32(v int32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+// writePeople writes PEOPLE.ADT with n records: an AutoIncrement ID, a
+// NAME and an AGE that is NULL for every third person.
+func writePeople(t *testing.T, dir string, n int) *adt.Table {
+	t.Helper()
+	var records [][]byte
+	for i := 1; i <= n; i++ {
+		age := int32(20 + i)
+		if i%3 == 0 {
+			age = -1 << 31
+		}
+		rec := append(le32(int32(i)), fmt.Sprintf("%-6s", fmt.Sprint("p", i))...)
+		records = append(records, append(rec, le32(age)...))
+	}
+	return writeADT(t, dir, "PEOPLE.ADT", []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Offset: 5, Length: 4},
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 9, Length: 6},
+		{Name: "AGE", Type: adt.ColumnTypeInt, Offset: 15, Length: 4},
+	}, records)
+}
+
+// rows returns every row of tableName ordered by ID, formatted.
+func rows(t *testing.T, db *sqlx.DB, tableName string) []string {
+	t.Helper()
+	r, err := db.Queryx(`SELECT * FROM "` + tableName + `" ORDER BY "ID"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var result []string
+	for r.Next() {
+		values, err := r.SliceScan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, fmt.Sprint(values))
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
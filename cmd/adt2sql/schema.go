@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+)
+
+// columnsQueries list the columns of a table, in order, with their data
+// type and whether they accept NULL, per dialect.
+var columnsQueries = map[string]string{
+	"mysql":     "SELECT column_name AS col_name, data_type AS col_type, is_nullable AS col_nullable FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position",
+	"postgres":  "SELECT column_name AS col_name, data_type AS col_type, is_nullable AS col_nullable FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position",
+	"sqlserver": "SELECT column_name AS col_name, data_type AS col_type, is_nullable AS col_nullable FROM information_schema.columns WHERE table_name = @p1 ORDER BY ordinal_position",
+	"sqlite":    "SELECT name AS col_name, type AS col_type, CASE WHEN \"notnull\" = 0 THEN 'YES' ELSE 'NO' END AS col_nullable FROM pragma_table_info(?) ORDER BY cid",
+}
+
+// targetColumns returns the columns of tableName in the target database,
+// or none if it doesn't exist.
+func targetColumns(db *sqlx.DB, dialect adt.Dialect, tableName string) ([]adt.SQLColumn, error) {
+	query, ok := columnsQueries[dialect.Name()]
+	if !ok {
+		return nil, fmt.Errorf("can't inspect %s tables", dialect.Name())
+	}
+	var rows []struct {
+		Name     string `db:"col_name"`
+		Type     string `db:"col_type"`
+		Nullable string `db:"col_nullable"`
+	}
+	if err := db.Select(&rows, query, tableName); err != nil {
+		return nil, err
+	}
+	result := make([]adt.SQLColumn, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, adt.SQLColumn{Name: r.Name, Type: r.Type, Nullable: r.Nullable == "YES"})
+	}
+	return result, nil
+}
+
+// createTable creates tableName if it doesn't exist. If it does, its
+// columns are compared with the table's and ALTER TABLE statements are
+// printed, and applied with -alter. Columns of another type are reported.
+// Loading fails if columns are missing from the target and -alter isn't
+// set, or if target columns can't hold the ADT table's NULLs.
+func createTable(db *sqlx.DB, dialect adt.Dialect, table *adt.Table, tableName string) error {
+	existing, err := targetColumns(db, dialect, tableName)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		ddl, err := table.DialectDDL(dialect, tableName)
+		if err != nil {
+			return err
+		}
+		if *flagVerbose {
+			fmt.Println(ddl)
+		}
+		_, err = db.Exec(ddl)
+		return err
+	}
+
+	diff, err := table.Diff(dialect, existing)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+	for _, c := range diff.Added {
+		log.Printf("%s: column %s is missing from the target table", tableName, c.Name)
+	}
+	for _, name := range diff.Dropped {
+		log.Printf("%s: target column %s is no longer in the ADT table", tableName, name)
+	}
+	var notNull []string
+	for _, ch := range diff.Changed {
+		null := "NOT NULL"
+		if ch.Existing.Nullable {
+			null = "NULL"
+		}
+		log.Printf("%s: target column %s is %s %s, expected %s", tableName, ch.Existing.Name, ch.Existing.Type, null, dialect.ColumnType(ch.Column))
+		if !ch.Existing.Nullable && ch.Nullable {
+			notNull = append(notNull, ch.Existing.Name)
+		}
+	}
+	if len(notNull) > 0 {
+		return fmt.Errorf("target columns %s are NOT NULL but can be empty in the ADT table", strings.Join(notNull, ", "))
+	}
+	stmts := diff.AlterSQL(dialect, tableName, *flagDrop)
+	for _, stmt := range stmts {
+		fmt.Println(stmt + ";")
+	}
+	if !*flagAlter {
+		if len(diff.Added) > 0 {
+			return fmt.Errorf("target table schema is out of date; rerun with -alter to apply the statements above")
+		}
+		return nil
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build !nosqlite
+// +build !nosqlite
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/adt"
+)
+
+// TestSQLiteRoundTrip loads a table into a SQLite file, and runs a dump of
+// it into another, expecting the same rows in both.
+func TestSQLiteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	table := writePeople(t, dir, 250)
+	*flagProgress = 0
+
+	db, err := openSQLite(filepath.Join(dir, "load.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	n, err := loadTable(db, adt.SQLite, table, "PEOPLE")
+	if err != nil || n != 250 {
+		t.Fatalf("loadTable() = %d, %v; want 250 rows", n, err)
+	}
+	loaded := rows(t, db, "PEOPLE")
+	if len(loaded) != 250 || loaded[0] != "[1 p1 21]" || loaded[2] != "[3 p3 <nil>]" {
+		t.Fatalf("loaded %d rows, starting %q", len(loaded), loaded[:3])
+	}
+
+	var script bytes.Buffer
+	w := bufio.NewWriter(&script)
+	if _, err := dumpTable(w, adt.SQLite, table, "PEOPLE"); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	dumped, err := openSQLite(filepath.Join(dir, "dump.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dumped.Close()
+	if _, err := dumped.Exec(script.String()); err != nil {
+		t.Fatalf("running the dump: %v\n%s", err, script.Bytes()[:200])
+	}
+	if got := rows(t, dumped, "PEOPLE"); !reflect.DeepEqual(got, loaded) {
+		t.Errorf("dumped rows differ from loaded ones: %q... vs %q...", got[:3], loaded[:3])
+	}
+}
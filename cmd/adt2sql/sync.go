@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tmc/adt"
+)
+
+// deleteBatch is the number of keys per DELETE statement.
+const deleteBatch = 1000
+
+// tableState is the watermark recorded for a table by a sync.
+type tableState struct {
+	// Records is the record count at the last sync; records past it are new.
+	Records int `json:"records"`
+	// LastKey is the primary key of the last of those records. A different
+	// key at its position means the table was packed.
+	LastKey string `json:"last_key,omitempty"`
+	// FileModTime is the modification time of the ADT file at the last sync.
+	FileModTime time.Time `json:"file_mod_time"`
+	// ModTime and RowVersion are the highest values of the table's ModTime
+	// or RowVersion column seen so far.
+	ModTime    *time.Time `json:"mod_time,omitempty"`
+	RowVersion uint64     `json:"row_version,omitempty"`
+	SyncedAt   time.Time  `json:"synced_at"`
+}
+
+// syncState holds the watermarks of every synced table, persisted as JSON.
+type syncState struct {
+	mu     sync.Mutex
+	path   string
+	Tables map[string]*tableState `json:"tables"`
+}
+
+func loadState(path string) (*syncState, error) {
+	state := &syncState{path: path, Tables: map[string]*tableState{}}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(state); err != nil {
+		return nil, fmt.Errorf("reading sync state %s: %v", path, err)
+	}
+	return state, nil
+}
+
+func (s *syncState) get(table string) *tableState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.Tables[table]; ok {
+		result := *st
+		return &result
+	}
+	return &tableState{}
+}
+
+// set records the state of table and rewrites the state file.
+func (s *syncState) set(table string, st *tableState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Tables[table] = st
+	buf, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// changeColumn returns the table's ModTime or RowVersion column, if any.
+func changeColumn(table *adt.Table) *adt.Column {
+	for _, c := range table.Columns {
+		if c.Type == adt.ColumnTypeModTime || c.Type == adt.ColumnTypeRowVersion {
+			return c
+		}
+	}
+	return nil
+}
+
+// changed reports whether r may have been modified since the watermark in
+// st. ModTime values only count milliseconds, so a record modified in the
+// same tick as the watermark, after the last sync read it, is only told
+// apart by being sent again; upserting it again is harmless.
+func (st *tableState) changed(column *adt.Column, r adt.Record) bool {
+	if column == nil {
+		return false
+	}
+	switch v := r[column.Name].(type) {
+	case time.Time:
+		return st.ModTime == nil || !v.Before(*st.ModTime)
+	case uint64:
+		return v > st.RowVersion
+	}
+	return false
+}
+
+// advance moves the watermark in st past r.
+func (st *tableState) advance(column *adt.Column, r adt.Record) {
+	if column == nil {
+		return
+	}
+	switch v := r[column.Name].(type) {
+	case time.Time:
+		if st.ModTime == nil || v.After(*st.ModTime) {
+			st.ModTime = &v
+		}
+	case uint64:
+		if v > st.RowVersion {
+			st.RowVersion = v
+		}
+	}
+}
+
+// syncTable brings tableName up to date with the ADT file at path. Records
+// appended since the last sync, and records whose ModTime column reached
+// the watermark or whose RowVersion column passed it, are upserted on the
+// primary key, and rows whose record is marked deleted are removed. Tables
+// without such a column, and tables packed since the last sync (which
+// renumbers their records), are synced in full: every record is upserted
+// and rows whose key no longer exists are removed.
+func syncTable(db *sqlx.DB, dialect adt.Dialect, table *adt.Table, path, tableName string, state *syncState) (int, error) {
+	pk, err := table.GetPK()
+	if err != nil {
+		return 0, err
+	}
+	if pk == nil {
+		return 0, adt.ErrNoPK
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	count := int(table.RecordCount)
+	prev := state.get(tableName)
+	if prev.Records == count && prev.FileModTime.Equal(fi.ModTime()) {
+		log.Println(tableName, "unchanged since", prev.SyncedAt.Format(time.RFC3339))
+		return 0, nil
+	}
+
+	change := changeColumn(table)
+	full, err := needsFullSync(table, pk, prev)
+	if err != nil {
+		return 0, err
+	}
+	if change == nil {
+		full = true
+		log.Println(tableName, "has no ModTime or RowVersion column, syncing every record")
+	} else if full {
+		log.Println(tableName, "was packed since the last sync, syncing every record")
+	}
+
+	if err := createTable(db, dialect, table, tableName); err != nil {
+		return 0, err
+	}
+
+	next := &tableState{
+		Records:     count,
+		FileModTime: fi.ModTime(),
+		ModTime:     prev.ModTime,
+		RowVersion:  prev.RowVersion,
+	}
+	var deleted []interface{}
+	active := make(map[string]bool)
+	scan := func(emit func(adt.Record) error) error {
+		for i := 0; i < count; i++ {
+			raw, err := table.RawRecord(i)
+			if err != nil {
+				return err
+			}
+			r, err := table.DecodeRecord(raw)
+			if err != nil {
+				return err
+			}
+			if i == count-1 {
+				next.LastKey = fmt.Sprint(r[pk.Name])
+			}
+			if raw[0] == adt.RecordFlagDeleted {
+				deleted = append(deleted, r[pk.Name])
+				continue
+			}
+			if full {
+				active[fmt.Sprint(r[pk.Name])] = true
+			}
+			if full || i >= prev.Records || prev.changed(change, r) {
+				if err := emit(r); err != nil {
+					return err
+				}
+			}
+			next.advance(change, r)
+		}
+		return nil
+	}
+
+	p := newProgress(tableName, count, *flagProgress)
+	err = execBatches(db, dialect, table, tableName, scan, func(rows int) (string, error) {
+		return table.BatchUpsertSQL(dialect, tableName, rows)
+	}, p)
+	if err == nil {
+		err = resetSequences(db, dialect, table, tableName)
+	}
+	if err != nil {
+		return p.done, err
+	}
+	if full {
+		stale, err := staleKeys(db, dialect, tableName, pk.Name, active)
+		if err != nil {
+			return p.done, err
+		}
+		deleted = append(deleted, stale...)
+	}
+	if err := deleteKeys(db, dialect, tableName, pk.Name, deleted); err != nil {
+		return p.done, err
+	}
+	log.Printf("%s %d rows upserted, %d deleted keys removed in %v", tableName, p.done, len(deleted), time.Since(p.start))
+
+	next.SyncedAt = time.Now()
+	return p.done, state.set(tableName, next)
+}
+
+// needsFullSync reports whether the records synced before, as recorded in
+// prev, can no longer be told from new ones by their position: the table
+// shrank, or the record at the old high-water mark has another key, as
+// happens when a packed table grows again. State from before LastKey was
+// recorded can't be checked and also needs a full sync.
+func needsFullSync(table *adt.Table, pk *adt.Column, prev *tableState) (bool, error) {
+	if prev.Records == 0 {
+		return false, nil
+	}
+	if int(table.RecordCount) < prev.Records || prev.LastKey == "" {
+		return true, nil
+	}
+	r, err := table.Get(prev.Records - 1)
+	if err != nil {
+		return false, err
+	}
+	return fmt.Sprint(r[pk.Name]) != prev.LastKey, nil
+}
+
+// staleKeys returns the keys in tableName that are not in active.
+func staleKeys(db *sqlx.DB, dialect adt.Dialect, tableName, key string, active map[string]bool) ([]interface{}, error) {
+	rows, err := db.Query("SELECT " + dialect.QuoteIdent(key) + " FROM " + dialect.QuoteIdent(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []interface{}
+	for rows.Next() {
+		var k int64
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		if !active[fmt.Sprint(k)] {
+			result = append(result, k)
+		}
+	}
+	return result, rows.Err()
+}
+
+// deleteKeys deletes the rows of tableName whose key is in keys.
+func deleteKeys(db *sqlx.DB, dialect adt.Dialect, tableName, key string, keys []interface{}) error {
+	for start := 0; start < len(keys); start += deleteBatch {
+		end := start + deleteBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		placeHolders := make([]string, 0, end-start)
+		for i := range keys[start:end] {
+			placeHolders = append(placeHolders, dialect.Placeholder(i+1))
+		}
+		query := "DELETE FROM " + dialect.QuoteIdent(tableName) + " WHERE " + dialect.QuoteIdent(key) +
+			" IN (" + strings.Join(placeHolders, ",") + ")"
+		if _, err := db.Exec(query, keys[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build !nosqlite
+// +build !nosqlite
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tmc/adt"
+)
+
+// TestSyncSameTick syncs a table, changes a record without moving its
+// ModTime past the watermark, as happens within one millisecond, and syncs
+// again, expecting the change to arrive and the rows sent again to upsert
+// cleanly.
+func TestSyncSameTick(t *testing.T) {
+	dir := t.TempDir()
+	*flagProgress = 0
+	path := filepath.Join(dir, "ITEMS.ADT")
+	write := func(names ...string) *adt.Table {
+		var records [][]byte
+		for i, name := range names {
+			rec := append(le32(int32(i+1)), fmt.Sprintf("%-6s", name)...)
+			rec = append(rec, le32(2460000)...)
+			records = append(records, append(rec, le32(1000)...))
+		}
+		table := writeADT(t, dir, "ITEMS.ADT", []*adt.Column{
+			{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Offset: 5, Length: 4},
+			{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 9, Length: 6},
+			{Name: "CHANGED", Type: adt.ColumnTypeModTime, Offset: 15, Length: 8},
+		}, records)
+		// Move the file's modification time on so the sync doesn't skip it.
+		mtime := time.Now().Add(time.Duration(len(names)) * time.Minute)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return table
+	}
+	state, err := loadState(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := openSQLite(filepath.Join(dir, "sync.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, step := range []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"a", "b"}, []string{"a", "b"}},
+		{[]string{"a", "b2"}, []string{"a", "b2"}},
+		{[]string{"a", "b2", "c"}, []string{"a", "b2", "c"}},
+	} {
+		table := write(step.names...)
+		if _, err := syncTable(db, adt.SQLite, table, path, "ITEMS", state); err != nil {
+			t.Fatalf("syncing %q: %v", step.names, err)
+		}
+		var got []string
+		if err := db.Select(&got, `SELECT "NAME" FROM "ITEMS" ORDER BY "ID"`); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("after syncing %q, rows = %q, want %q", step.names, got, step.want)
+		}
+	}
+}
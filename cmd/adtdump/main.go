@@ -1,30 +1,217 @@
-// Command adtdump dumps information about an ADT database
+// Command adtdump prints a report describing an ADT table: its header,
+// columns, record counts, memo file and the path and size of its index
+// file. Index tags are not listed: the .ADI format isn't decoded by this
+// package (see Table.Indexes). With -hex it prints an annotated hex dump of
+// a record instead.
 package main
 
 import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/tmc/adt"
 )
 
-var flagFile = flag.String("f", "", "path to ADT file")
+var (
+	flagFile = flag.String("f", "", "path to ADT file")
+	flagHex  = flag.Int("hex", -1, "print an annotated hex dump of this record (0-based) instead of the report")
+)
 
 func main() {
 	flag.Parse()
-	table, err := adt.TableFromPath(*flagFile)
-	if err != nil {
+	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	spew.Dump(table)
-	r, err := table.Get(int(table.RecordCount - 2))
-	fmt.Printf("%+v\n", r)
+}
 
+func run() error {
+	table, err := adt.TableFromPath(*flagFile)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		return err
+	}
+	defer table.Close()
+	if *flagHex >= 0 {
+		return hexDump(table, *flagHex)
+	}
+	return report(table, *flagFile)
+}
+
+// sibling returns the path of the file next to path with extension ext, and
+// its size, or -1 if there is none.
+func sibling(path, ext string) (string, int64) {
+	p := strings.TrimSuffix(path, filepath.Ext(path)) + ext
+	fi, err := os.Stat(p)
+	if err != nil {
+		return p, -1
+	}
+	return p, fi.Size()
+}
+
+func report(table *adt.Table, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n\n", table.Name)
+	fmt.Printf("header\n")
+	fmt.Printf("  file size:     %d\n", fi.Size())
+	fmt.Printf("  record count:  %d\n", table.RecordCount)
+	fmt.Printf("  data offset:   %d\n", table.DataOffset)
+	fmt.Printf("  record length: %d\n", table.RecordLength)
+	expected := int64(table.DataOffset) + int64(table.RecordCount)*int64(table.RecordLength)
+	if expected != fi.Size() {
+		fmt.Printf("  warning:       header implies %d bytes, file has %d\n", expected, fi.Size())
+	}
+
+	fmt.Printf("\ncolumns (%d)\n", len(table.Columns))
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "  #\tNAME\tTYPE\tOFFSET\tLENGTH\tDECIMALS\tNULLABLE")
+	for i, c := range table.Columns {
+		fmt.Fprintf(w, "  %d\t%s\t%s\t%d\t%d\t%d\t%v\n", i, c.Name, strings.TrimPrefix(c.Type.String(), "ColumnType"),
+			c.Offset, c.Length, c.DecimalDigits, c.Nullable())
+	}
+	w.Flush()
+
+	var memoColumns []*adt.Column
+	for _, c := range table.Columns {
+		if c.Type == adt.ColumnTypeMemo {
+			memoColumns = append(memoColumns, c)
+		}
+	}
+	deleted, memos, memoBytes, maxMemo := 0, 0, 0, 0
+	for i := 0; i < int(table.RecordCount); i++ {
+		raw, err := table.RawRecord(i)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		if len(raw) > 0 && raw[0] == adt.RecordFlagDeleted {
+			deleted++
+		}
+		for _, c := range memoColumns {
+			if int(c.Offset)+int(c.Length) > len(raw) {
+				continue
+			}
+			v, err := adt.ReadValue(raw, c)
+			if err != nil {
+				return fmt.Errorf("record %d: %v", i, err)
+			}
+			if m := v.(adt.MemoField); m.Length > 0 {
+				memos++
+				memoBytes += int(m.Length)
+				if int(m.Length) > maxMemo {
+					maxMemo = int(m.Length)
+				}
+			}
+		}
+	}
+	fmt.Printf("\nrecords\n")
+	fmt.Printf("  total:   %d\n", table.RecordCount)
+	fmt.Printf("  active:  %d\n", int(table.RecordCount)-deleted)
+	fmt.Printf("  deleted: %d\n", deleted)
+
+	fmt.Printf("\nmemo file\n")
+	admPath, admSize := sibling(path, ".ADM")
+	if admSize < 0 {
+		fmt.Printf("  none\n")
+	} else {
+		fmt.Printf("  path:       %s\n", admPath)
+		fmt.Printf("  size:       %d (%d 8-byte blocks)\n", admSize, admSize/8)
+		fmt.Printf("  memos:      %d in %d columns\n", memos, len(memoColumns))
+		fmt.Printf("  memo bytes: %d (largest %d)\n", memoBytes, maxMemo)
+	}
+
+	fmt.Printf("\nindex file\n")
+	adiPath, adiSize := sibling(path, ".ADI")
+	if adiSize < 0 {
+		fmt.Printf("  none\n")
+	} else {
+		fmt.Printf("  path: %s\n", adiPath)
+		fmt.Printf("  size: %d\n", adiSize)
+	}
+	return nil
+}
+
+// hexDump prints record as hex, one line per field, with the decoded value.
+func hexDump(table *adt.Table, record int) error {
+	if record >= int(table.RecordCount) {
+		return fmt.Errorf("record %d out of range (%d records)", record, table.RecordCount)
+	}
+	raw, err := table.RawRecord(record)
+	if err != nil {
+		return err
+	}
+	offset := int64(table.DataOffset) + int64(table.RecordLength)*int64(record)
+	fmt.Printf("%s record %d at file offset %d (0x%x), %d bytes\n\n", table.Name, record, offset, offset, len(raw))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "OFFSET\tFIELD\tBYTES\tVALUE")
+	flag := "active"
+	if len(raw) > 0 && raw[0] == adt.RecordFlagDeleted {
+		flag = "deleted"
+	}
+	pos := 5
+	if len(raw) < pos {
+		pos = len(raw)
+	}
+	fmt.Fprintf(w, "%04x\t(flags)\t%s\t%s\n", 0, hexBytes(raw[:pos]), flag)
+	for _, c := range table.Columns {
+		start, end := int(c.Offset), int(c.Offset)+int(c.Length)
+		if end > len(raw) {
+			fmt.Fprintf(w, "%04x\t%s\t\tfield extends past the record (%d > %d)\n", start, c.Name, end, len(raw))
+			continue
+		}
+		if start > pos {
+			fmt.Fprintf(w, "%04x\t(gap)\t%s\t\n", pos, hexBytes(raw[pos:start]))
+		}
+		value, err := adt.ReadValue(raw, c)
+		desc := describe(value)
+		if err != nil {
+			desc = "error: " + err.Error()
+		}
+		for i := start; i < end; i += 16 {
+			j := i + 16
+			if j > end {
+				j = end
+			}
+			if i == start {
+				fmt.Fprintf(w, "%04x\t%s\t%s\t%s\n", i, c.Name, hexBytes(raw[i:j]), desc)
+			} else {
+				fmt.Fprintf(w, "%04x\t\t%s\t\n", i, hexBytes(raw[i:j]))
+			}
+		}
+		if end > pos {
+			pos = end
+		}
+	}
+	if pos < len(raw) {
+		fmt.Fprintf(w, "%04x\t(unused)\t%s\t\n", pos, hexBytes(raw[pos:]))
+	}
+	return w.Flush()
+}
+
+func hexBytes(b []byte) string {
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(parts, " ")
+}
+
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []byte:
+		return fmt.Sprintf("%q", adt.DecodeString(v))
+	case adt.MemoField:
+		return fmt.Sprintf("memo block %d, %d bytes", v.BlockOffset, v.Length)
 	}
+	return fmt.Sprint(v)
 }
@@ -9,7 +9,10 @@ import (
 	"strings"
 )
 
-var ErrReadingColumnDescriptor = errors.New("adt: error reading column descriptor")
+var (
+	ErrReadingColumnDescriptor = errors.New("adt: error reading column descriptor")
+	ErrColumnWidth             = errors.New("adt: column too narrow for its type")
+)
 
 type Column struct {
 	Name          string
@@ -47,6 +50,33 @@ func ColumnFromReader(r io.Reader) (*Column, error) {
 	return column, nil
 }
 
+// CurrencyPrecision is the number of decimal digits a currency value can
+// have: ADS stores currency as a 64-bit integer.
+const CurrencyPrecision = 19
+
+// CurrencyScale returns the number of decimal digits stored for a currency
+// column; ADS stores currency with four implied decimals by default.
+func (c *Column) CurrencyScale() int {
+	if c.DecimalDigits == 0 {
+		return 4
+	}
+	return int(c.DecimalDigits)
+}
+
+// Nullable reports whether values read from the column may be nil.
+func (c *Column) Nullable() bool {
+	switch c.Type {
+	case ColumnTypeBool, ColumnTypeCharacter, ColumnTypeCiCharacter, ColumnTypeAutoIncrement, ColumnTypeRowVersion, ColumnTypeTime, ColumnTypeBlob, ColumnTypeMemo:
+		return false
+	}
+	return true
+}
+
+// SQLType returns the MySQL column type for c, sized from its descriptor.
+func (c *Column) SQLType() string {
+	return MySQL.ColumnType(c)
+}
+
 func (c Column) String() string {
 	return fmt.Sprintf("%s (%s)", c.Name, c.Type)
 }
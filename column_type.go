@@ -16,33 +16,47 @@ const (
 	ColumnTypeTime          ColumnType = 13
 	ColumnTypeTimestamp     ColumnType = 14
 	ColumnTypeCurrency      ColumnType = 17
+	ColumnTypeRowVersion    ColumnType = 21
+	ColumnTypeModTime       ColumnType = 22
 )
 
+// known reports whether ct is one of the types ReadValue decodes.
+func (ct ColumnType) known() bool {
+	switch ct {
+	case ColumnTypeBool, ColumnTypeCharacter, ColumnTypeMemo, ColumnTypeBlob, ColumnTypeDouble,
+		ColumnTypeInt, ColumnTypeShortInt, ColumnTypeCiCharacter, ColumnTypeAutoIncrement,
+		ColumnTypeDate, ColumnTypeTime, ColumnTypeTimestamp, ColumnTypeCurrency,
+		ColumnTypeRowVersion, ColumnTypeModTime:
+		return true
+	}
+	return false
+}
+
+// width returns the number of bytes ReadValue decodes from a column of
+// type ct, or 0 if it takes any length.
+func (ct ColumnType) width() int {
+	switch ct {
+	case ColumnTypeBool:
+		return 1
+	case ColumnTypeShortInt:
+		return 2
+	case ColumnTypeInt, ColumnTypeAutoIncrement, ColumnTypeDate, ColumnTypeTime:
+		return 4
+	case ColumnTypeMemo:
+		return 6
+	case ColumnTypeDouble, ColumnTypeCurrency, ColumnTypeTimestamp, ColumnTypeModTime, ColumnTypeRowVersion:
+		return 8
+	}
+	return 0
+}
+
 type MemoField struct {
 	BlockOffset uint32
 	Length      uint16
 }
 
-var sqlTypes = map[ColumnType]string{
-	ColumnTypeBool:          "BOOLEAN",
-	ColumnTypeCharacter:     "VARCHAR(255)",
-	ColumnTypeMemo:          "BLOB",
-	ColumnTypeBlob:          "BLOB",
-	ColumnTypeDouble:        "DOUBLE",
-	ColumnTypeInt:           "INTEGER",
-	ColumnTypeShortInt:      "SMALLINT",
-	ColumnTypeCiCharacter:   "VARCHAR(255)",
-	ColumnTypeAutoIncrement: "INTEGER PRIMARY KEY", // AUTO_INCREMENT",
-	ColumnTypeDate:          "DATE",
-	ColumnTypeTime:          "TIME",
-	ColumnTypeTimestamp:     "DATETIME",
-	ColumnTypeCurrency:      "DECIMAL(65,4)",
-}
-
+// SQLType returns a MySQL column type for ct. Character columns are assumed
+// to hold 255 characters; use Column.SQLType to size from the descriptor.
 func (ct ColumnType) SQLType() string {
-	t, ok := sqlTypes[ct]
-	if ok {
-		return t
-	}
-	return "VARCHAR(100)"
+	return MySQL.ColumnType(&Column{Type: ct, Length: 255})
 }
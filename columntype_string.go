@@ -9,7 +9,7 @@ const (
 	_ColumnType_name_1 = "ColumnTypeDateColumnTypeCharacterColumnTypeMemoColumnTypeBlob"
 	_ColumnType_name_2 = "ColumnTypeDoubleColumnTypeIntColumnTypeShortIntColumnTypeTimeColumnTypeTimestampColumnTypeAutoIncrement"
 	_ColumnType_name_3 = "ColumnTypeCurrency"
-	_ColumnType_name_4 = "ColumnTypeCiCharacter"
+	_ColumnType_name_4 = "ColumnTypeCiCharacterColumnTypeRowVersionColumnTypeModTime"
 )
 
 var (
@@ -17,7 +17,7 @@ var (
 	_ColumnType_index_1 = [...]uint8{0, 14, 33, 47, 61}
 	_ColumnType_index_2 = [...]uint8{0, 16, 29, 47, 61, 80, 103}
 	_ColumnType_index_3 = [...]uint8{0, 18}
-	_ColumnType_index_4 = [...]uint8{0, 21}
+	_ColumnType_index_4 = [...]uint8{0, 21, 41, 58}
 )
 
 func (i ColumnType) String() string {
@@ -32,8 +32,9 @@ func (i ColumnType) String() string {
 		return _ColumnType_name_2[_ColumnType_index_2[i]:_ColumnType_index_2[i+1]]
 	case i == 17:
 		return _ColumnType_name_3
-	case i == 20:
-		return _ColumnType_name_4
+	case 20 <= i && i <= 22:
+		i -= 20
+		return _ColumnType_name_4[_ColumnType_index_4[i]:_ColumnType_index_4[i+1]]
 	default:
 		return fmt.Sprintf("ColumnType(%d)", i)
 	}
@@ -0,0 +1,377 @@
+package adt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownDialect    = errors.New("adt: unknown sql dialect")
+	ErrUpsertUnsupported = errors.New("adt: dialect does not support upserts")
+)
+
+// Dialect describes the SQL flavor used when generating DDL and INSERT
+// statements for a table.
+type Dialect interface {
+	// Name returns the canonical name of the dialect.
+	Name() string
+	// QuoteIdent quotes a table or column name.
+	QuoteIdent(name string) string
+	// Placeholder returns the bind parameter for the n'th (1-based) value.
+	Placeholder(n int) string
+	// ColumnType returns the column definition type for c.
+	ColumnType(c *Column) string
+	// CreateTable returns the statement prefix that creates the named
+	// (already quoted) table if it does not exist.
+	CreateTable(quotedName string) string
+	// TableOptions returns options appended after the column list.
+	TableOptions() string
+	// CreateIndex returns the statement prefix that creates the named index
+	// on a table if it does not exist.
+	CreateIndex(index, table string) string
+	// UpsertClause returns the clause appended to an INSERT statement that
+	// updates columns of existing rows whose key conflicts, or "" if the
+	// dialect has no such clause.
+	UpsertClause(key, columns []string) string
+	// Literal returns v, a value read from column c, as a SQL literal.
+	Literal(c *Column, v interface{}) string
+	// IdentityInsert returns the statement that allows (on) or forbids
+	// inserting explicit values into the AutoIncrement column of a table,
+	// or "" if the dialect always allows it.
+	IdentityInsert(table string, on bool) string
+	// ResetSequence returns the statement that moves the generator of a
+	// table's AutoIncrement column past its largest value, or "" if the
+	// dialect does so by itself.
+	ResetSequence(table, column string) string
+}
+
+// Supported dialects.
+var (
+	MySQL      Dialect = mysqlDialect{}
+	PostgreSQL Dialect = postgresDialect{}
+	SQLite     Dialect = sqliteDialect{}
+	SQLServer  Dialect = sqlserverDialect{}
+)
+
+var dialects = map[string]Dialect{
+	"mysql":      MySQL,
+	"postgres":   PostgreSQL,
+	"postgresql": PostgreSQL,
+	"sqlite":     SQLite,
+	"sqlite3":    SQLite,
+	"sqlserver":  SQLServer,
+	"mssql":      SQLServer,
+}
+
+// LookupDialect returns the dialect registered under name, which is
+// typically a database/sql driver name or URL scheme.
+func LookupDialect(name string) (Dialect, error) {
+	d, ok := dialects[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%v: %q", ErrUnknownDialect, name)
+	}
+	return d, nil
+}
+
+// charLength returns the declared length of a character column, never zero.
+func charLength(c *Column) int {
+	if c.Length == 0 {
+		return 1
+	}
+	return int(c.Length)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func (mysqlDialect) Placeholder(n int) string { return "?" }
+
+func (mysqlDialect) ColumnType(c *Column) string {
+	switch c.Type {
+	case ColumnTypeBool:
+		return "BOOLEAN"
+	case ColumnTypeCharacter, ColumnTypeCiCharacter:
+		if n := charLength(c); n <= 16383 {
+			return fmt.Sprintf("VARCHAR(%d)", n)
+		}
+		return "TEXT"
+	case ColumnTypeMemo:
+		return "TEXT"
+	case ColumnTypeBlob:
+		return "BLOB"
+	case ColumnTypeDouble:
+		return "DOUBLE"
+	case ColumnTypeInt:
+		return "INTEGER"
+	case ColumnTypeShortInt:
+		return "SMALLINT"
+	case ColumnTypeAutoIncrement:
+		return "INT UNSIGNED AUTO_INCREMENT"
+	case ColumnTypeDate:
+		return "DATE"
+	case ColumnTypeTime:
+		return "TIME(3)"
+	case ColumnTypeTimestamp, ColumnTypeModTime:
+		return "DATETIME(3)"
+	case ColumnTypeRowVersion:
+		return "BIGINT UNSIGNED"
+	case ColumnTypeCurrency:
+		return fmt.Sprintf("DECIMAL(%d,%d)", CurrencyPrecision, c.CurrencyScale())
+	}
+	return "VARCHAR(100)"
+}
+
+func (mysqlDialect) CreateTable(quotedName string) string {
+	return "CREATE TABLE IF NOT EXISTS " + quotedName
+}
+
+func (mysqlDialect) TableOptions() string {
+	return "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+}
+
+// CreateIndex for MySQL cannot guard against existing indexes.
+func (d mysqlDialect) CreateIndex(index, table string) string {
+	return "CREATE INDEX " + d.QuoteIdent(index) + " ON " + d.QuoteIdent(table)
+}
+
+func (d mysqlDialect) UpsertClause(key, columns []string) string {
+	sets := make([]string, 0, len(columns))
+	for _, c := range columns {
+		sets = append(sets, d.QuoteIdent(c)+"=VALUES("+d.QuoteIdent(c)+")")
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+}
+
+func (mysqlDialect) Literal(c *Column, v interface{}) string { return mysqlLiterals.literal(c, v) }
+
+func (mysqlDialect) IdentityInsert(table string, on bool) string { return "" }
+
+func (mysqlDialect) ResetSequence(table, column string) string { return "" }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) ColumnType(c *Column) string {
+	switch c.Type {
+	case ColumnTypeBool:
+		return "BOOLEAN"
+	case ColumnTypeCharacter, ColumnTypeCiCharacter:
+		return fmt.Sprintf("VARCHAR(%d)", charLength(c))
+	case ColumnTypeMemo:
+		return "TEXT"
+	case ColumnTypeBlob:
+		return "BYTEA"
+	case ColumnTypeDouble:
+		return "DOUBLE PRECISION"
+	case ColumnTypeInt:
+		return "INTEGER"
+	case ColumnTypeShortInt:
+		return "SMALLINT"
+	case ColumnTypeAutoIncrement:
+		return "BIGSERIAL"
+	case ColumnTypeDate:
+		return "DATE"
+	case ColumnTypeTime:
+		return "TIME(3)"
+	case ColumnTypeTimestamp, ColumnTypeModTime:
+		return "TIMESTAMP(3)"
+	case ColumnTypeRowVersion:
+		return "NUMERIC(20)"
+	case ColumnTypeCurrency:
+		return fmt.Sprintf("NUMERIC(%d,%d)", CurrencyPrecision, c.CurrencyScale())
+	}
+	return "VARCHAR(100)"
+}
+
+func (postgresDialect) CreateTable(quotedName string) string {
+	return "CREATE TABLE IF NOT EXISTS " + quotedName
+}
+
+func (postgresDialect) TableOptions() string { return "" }
+
+func (d postgresDialect) CreateIndex(index, table string) string {
+	return "CREATE INDEX IF NOT EXISTS " + d.QuoteIdent(index) + " ON " + d.QuoteIdent(table)
+}
+
+func (d postgresDialect) UpsertClause(key, columns []string) string {
+	return onConflictUpdate(d, key, columns)
+}
+
+// onConflictUpdate returns the ON CONFLICT clause shared by PostgreSQL and
+// SQLite.
+func onConflictUpdate(d Dialect, key, columns []string) string {
+	quoted := make([]string, 0, len(key))
+	for _, k := range key {
+		quoted = append(quoted, d.QuoteIdent(k))
+	}
+	sets := make([]string, 0, len(columns))
+	for _, c := range columns {
+		sets = append(sets, d.QuoteIdent(c)+"=EXCLUDED."+d.QuoteIdent(c))
+	}
+	return "ON CONFLICT (" + strings.Join(quoted, ",") + ") DO UPDATE SET " + strings.Join(sets, ",")
+}
+
+func (postgresDialect) Literal(c *Column, v interface{}) string {
+	return postgresLiterals.literal(c, v)
+}
+
+func (postgresDialect) IdentityInsert(table string, on bool) string { return "" }
+
+// ResetSequence for PostgreSQL sets the column's BIGSERIAL sequence so that
+// the next value follows the largest key, since inserting explicit values
+// does not advance it. pg_get_serial_sequence takes the table as a quoted
+// name but the column as is.
+func (d postgresDialect) ResetSequence(table, column string) string {
+	quotedTable := d.QuoteIdent(table)
+	return "SELECT setval(pg_get_serial_sequence('" + strings.Replace(quotedTable, "'", "''", -1) + "', '" +
+		strings.Replace(column, "'", "''", -1) + "'), COALESCE(MAX(" + d.QuoteIdent(column) + "), 0) + 1, false) FROM " + quotedTable
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (sqliteDialect) Placeholder(n int) string { return "?" }
+
+// ColumnType for SQLite maps to one of the storage affinities; dates and
+// times are stored as ISO-8601 text.
+func (sqliteDialect) ColumnType(c *Column) string {
+	switch c.Type {
+	case ColumnTypeBool, ColumnTypeInt, ColumnTypeShortInt, ColumnTypeRowVersion:
+		return "INTEGER"
+	case ColumnTypeCharacter:
+		return "TEXT"
+	case ColumnTypeCiCharacter:
+		return "TEXT COLLATE NOCASE"
+	case ColumnTypeMemo:
+		return "TEXT"
+	case ColumnTypeBlob:
+		return "BLOB"
+	case ColumnTypeDouble:
+		return "REAL"
+	case ColumnTypeAutoIncrement:
+		return "INTEGER"
+	case ColumnTypeDate, ColumnTypeTime, ColumnTypeTimestamp, ColumnTypeModTime:
+		return "TEXT"
+	case ColumnTypeCurrency:
+		return "NUMERIC"
+	}
+	return "TEXT"
+}
+
+func (sqliteDialect) CreateTable(quotedName string) string {
+	return "CREATE TABLE IF NOT EXISTS " + quotedName
+}
+
+func (sqliteDialect) TableOptions() string { return "" }
+
+func (d sqliteDialect) CreateIndex(index, table string) string {
+	return "CREATE INDEX IF NOT EXISTS " + d.QuoteIdent(index) + " ON " + d.QuoteIdent(table)
+}
+
+func (d sqliteDialect) UpsertClause(key, columns []string) string {
+	return onConflictUpdate(d, key, columns)
+}
+
+func (sqliteDialect) Literal(c *Column, v interface{}) string { return sqliteLiterals.literal(c, v) }
+
+func (sqliteDialect) IdentityInsert(table string, on bool) string { return "" }
+
+func (sqliteDialect) ResetSequence(table, column string) string { return "" }
+
+type sqlserverDialect struct{}
+
+func (sqlserverDialect) Name() string { return "sqlserver" }
+
+func (sqlserverDialect) QuoteIdent(name string) string {
+	return "[" + strings.Replace(name, "]", "]]", -1) + "]"
+}
+
+func (sqlserverDialect) Placeholder(n int) string { return fmt.Sprintf("@p%d", n) }
+
+// ColumnType for SQL Server declares AutoIncrement columns as IDENTITY;
+// loading existing key values requires SET IDENTITY_INSERT.
+func (sqlserverDialect) ColumnType(c *Column) string {
+	switch c.Type {
+	case ColumnTypeBool:
+		return "BIT"
+	case ColumnTypeCharacter, ColumnTypeCiCharacter:
+		if n := charLength(c); n <= 4000 {
+			return fmt.Sprintf("NVARCHAR(%d)", n)
+		}
+		return "NVARCHAR(MAX)"
+	case ColumnTypeMemo:
+		return "NVARCHAR(MAX)"
+	case ColumnTypeBlob:
+		return "VARBINARY(MAX)"
+	case ColumnTypeDouble:
+		return "FLOAT"
+	case ColumnTypeInt:
+		return "INT"
+	case ColumnTypeShortInt:
+		return "SMALLINT"
+	case ColumnTypeAutoIncrement:
+		return "BIGINT IDENTITY(1,1)"
+	case ColumnTypeDate:
+		return "DATE"
+	case ColumnTypeTime:
+		return "TIME(3)"
+	case ColumnTypeTimestamp, ColumnTypeModTime:
+		return "DATETIME2(3)"
+	case ColumnTypeRowVersion:
+		return "DECIMAL(20)"
+	case ColumnTypeCurrency:
+		return fmt.Sprintf("DECIMAL(%d,%d)", CurrencyPrecision, c.CurrencyScale())
+	}
+	return "NVARCHAR(100)"
+}
+
+func (sqlserverDialect) CreateTable(quotedName string) string {
+	name := strings.Replace(quotedName, "'", "''", -1)
+	return "IF OBJECT_ID(N'" + name + "', N'U') IS NULL CREATE TABLE " + quotedName
+}
+
+func (sqlserverDialect) TableOptions() string { return "" }
+
+func (d sqlserverDialect) CreateIndex(index, table string) string {
+	quotedTable := d.QuoteIdent(table)
+	return "IF INDEXPROPERTY(OBJECT_ID(N'" + strings.Replace(quotedTable, "'", "''", -1) + "'), N'" +
+		strings.Replace(index, "'", "''", -1) + "', 'IndexID') IS NULL CREATE INDEX " + d.QuoteIdent(index) + " ON " + quotedTable
+}
+
+// UpsertClause for SQL Server is unsupported; upserts there require MERGE.
+func (sqlserverDialect) UpsertClause(key, columns []string) string { return "" }
+
+func (sqlserverDialect) Literal(c *Column, v interface{}) string {
+	return sqlserverLiterals.literal(c, v)
+}
+
+// IdentityInsert for SQL Server toggles IDENTITY_INSERT, which only one
+// table of a session may have on at a time.
+func (d sqlserverDialect) IdentityInsert(table string, on bool) string {
+	if on {
+		return "SET IDENTITY_INSERT " + d.QuoteIdent(table) + " ON"
+	}
+	return "SET IDENTITY_INSERT " + d.QuoteIdent(table) + " OFF"
+}
+
+// ResetSequence for SQL Server is unneeded: inserting a value above the
+// identity's current value advances it.
+func (sqlserverDialect) ResetSequence(table, column string) string { return "" }
@@ -0,0 +1,298 @@
+package export
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmc/adt"
+)
+
+// Binary renderings for memo and blob values.
+const (
+	BinaryText   = "text"
+	BinaryBase64 = "base64"
+	BinaryHex    = "hex"
+	BinaryOmit   = "omit"
+)
+
+// Quoting policies for CSV fields.
+const (
+	QuoteMinimal    = "minimal"    // only fields that need it
+	QuoteAll        = "all"        // every field
+	QuoteNonNumeric = "nonnumeric" // every field of a non-numeric column
+	QuoteNone       = "none"       // never; delimiters in values are not escaped
+)
+
+// Default layouts used to format dates, timestamps and times.
+const (
+	DefaultDateLayout      = "2006-01-02"
+	DefaultTimestampLayout = "2006-01-02 15:04:05.000"
+)
+
+// Format controls how values are rendered as text.
+type Format struct {
+	// Null is written for NULL values.
+	Null string
+	// DateLayout and TimestampLayout are time.Format layouts; empty uses
+	// the defaults above.
+	DateLayout      string
+	TimestampLayout string
+	// TimeLayout is a time.Format layout for Time columns; empty writes
+	// HH:MM:SS.mmm.
+	TimeLayout string
+	// Binary is one of the Binary constants; empty is BinaryText. With
+	// BinaryOmit, memo and blob columns are left out of the output.
+	Binary string
+}
+
+// Value formats v, read from column c, as text. ok is false when the value
+// should be left out entirely (binary values with BinaryOmit).
+func (f Format) Value(c *adt.Column, v interface{}) (s string, ok bool) {
+	switch v := v.(type) {
+	case nil:
+		return f.Null, true
+	case string:
+		return v, true
+	case []byte:
+		switch f.Binary {
+		case BinaryOmit:
+			return "", false
+		case BinaryBase64:
+			return base64.StdEncoding.EncodeToString(v), true
+		case BinaryHex:
+			return hex.EncodeToString(v), true
+		}
+		return adt.DecodeString(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case time.Duration:
+		if f.TimeLayout == "" {
+			return adt.FormatDuration(v), true
+		}
+		return time.Time{}.Add(v).Format(f.TimeLayout), true
+	case time.Time:
+		if c.Type == adt.ColumnTypeDate {
+			return v.Format(layoutOr(f.DateLayout, DefaultDateLayout)), true
+		}
+		return v.Format(layoutOr(f.TimestampLayout, DefaultTimestampLayout)), true
+	}
+	return fmt.Sprint(v), true
+}
+
+func layoutOr(layout, def string) string {
+	if layout == "" {
+		return def
+	}
+	return layout
+}
+
+// validBinary reports whether name is a known binary rendering.
+func validBinary(name string) bool {
+	switch name {
+	case "", BinaryText, BinaryBase64, BinaryHex, BinaryOmit:
+		return true
+	}
+	return false
+}
+
+// CSVOptions configures a CSVWriter.
+type CSVOptions struct {
+	Format
+	// Comma is the field delimiter; zero uses ','.
+	Comma rune
+	// Quote is one of the Quote constants; empty is QuoteMinimal.
+	Quote string
+	// Header writes a row of column names first.
+	Header bool
+	// Columns selects and orders the columns written; empty writes all
+	// columns in table order. Names match case-insensitively.
+	Columns []string
+	// Encoding is one of the Encoding constants; empty is EncodingUTF8.
+	Encoding string
+}
+
+// CSVWriter writes records of a table as delimited text.
+type CSVWriter struct {
+	opts    CSVOptions
+	columns []*adt.Column
+	w       *bufio.Writer
+	enc     io.Closer
+	header  bool
+}
+
+// NewCSVWriter returns a CSVWriter writing t's records to w.
+func NewCSVWriter(w io.Writer, t *adt.Table, opts CSVOptions) (*CSVWriter, error) {
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+	if opts.Comma == '"' || opts.Comma == '\r' || opts.Comma == '\n' {
+		return nil, fmt.Errorf("export: invalid delimiter %q", opts.Comma)
+	}
+	switch opts.Quote {
+	case "":
+		opts.Quote = QuoteMinimal
+	case QuoteMinimal, QuoteAll, QuoteNonNumeric, QuoteNone:
+	default:
+		return nil, fmt.Errorf("export: unknown quoting policy %q", opts.Quote)
+	}
+	if !validBinary(opts.Binary) {
+		return nil, fmt.Errorf("export: unknown binary format %q", opts.Binary)
+	}
+	columns, err := SelectColumns(t, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Binary == BinaryOmit {
+		columns = withoutBinary(columns)
+	}
+	cw := &CSVWriter{opts: opts, columns: columns, header: opts.Header}
+	enc, err := NewEncoder(w, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	cw.enc = enc
+	cw.w = bufio.NewWriter(enc)
+	return cw, nil
+}
+
+// SelectColumns returns the columns of t named in names, in that order. An
+// empty list selects every column.
+func SelectColumns(t *adt.Table, names []string) ([]*adt.Column, error) {
+	if len(names) == 0 {
+		return t.Columns, nil
+	}
+	columns := make([]*adt.Column, 0, len(names))
+	for _, name := range names {
+		var found *adt.Column
+		for _, c := range t.Columns {
+			if strings.EqualFold(c.Name, strings.TrimSpace(name)) {
+				found = c
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("export: no column %q in %s", name, t.Name)
+		}
+		columns = append(columns, found)
+	}
+	return columns, nil
+}
+
+// Write writes r as one row, preceded by the header row on the first call
+// if one was requested.
+func (w *CSVWriter) Write(r adt.Record) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	fields := make([]string, len(w.columns))
+	nulls := make([]bool, len(w.columns))
+	for i, c := range w.columns {
+		fields[i], _ = w.opts.Value(c, r[c.Name])
+		nulls[i] = r[c.Name] == nil
+	}
+	return w.writeRow(fields, nulls)
+}
+
+// writeHeader writes the header row if one is still pending.
+func (w *CSVWriter) writeHeader() error {
+	if !w.header {
+		return nil
+	}
+	w.header = false
+	names := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		names[i] = c.Name
+	}
+	return w.writeRow(names, nil)
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *CSVWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// Close flushes w and finishes its output encoding, writing the header row
+// if no records were written. It does not close the underlying writer.
+func (w *CSVWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return w.enc.Close()
+}
+
+// writeRow writes one row. nulls marks the fields holding NULL, which are
+// only quoted when they need to be; it is nil for the header row.
+func (w *CSVWriter) writeRow(fields []string, nulls []bool) error {
+	for i, field := range fields {
+		if i > 0 {
+			w.w.WriteRune(w.opts.Comma)
+		}
+		var quote bool
+		switch {
+		case w.opts.Quote == QuoteNone:
+		case w.opts.Quote == QuoteMinimal || nulls != nil && nulls[i]:
+			quote = w.needsQuotes(field)
+		case w.opts.Quote == QuoteAll:
+			quote = true
+		case w.opts.Quote == QuoteNonNumeric:
+			quote = nulls == nil || !numeric(w.columns[i])
+		}
+		w.writeField(field, quote)
+	}
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+func (w *CSVWriter) writeField(field string, quote bool) {
+	if !quote {
+		w.w.WriteString(field)
+		return
+	}
+	w.w.WriteByte('"')
+	w.w.WriteString(strings.Replace(field, `"`, `""`, -1))
+	w.w.WriteByte('"')
+}
+
+// needsQuotes follows encoding/csv: fields containing the delimiter, quotes
+// or line breaks, or starting with a space, are quoted.
+func (w *CSVWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsRune(field, w.opts.Comma) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	return field[0] == ' ' || field[0] == '\t'
+}
+
+// withoutBinary drops memo and blob columns.
+func withoutBinary(columns []*adt.Column) []*adt.Column {
+	var kept []*adt.Column
+	for _, c := range columns {
+		if c.Type != adt.ColumnTypeMemo && c.Type != adt.ColumnTypeBlob {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// numeric reports whether c holds numbers.
+func numeric(c *adt.Column) bool {
+	switch c.Type {
+	case adt.ColumnTypeDouble, adt.ColumnTypeInt, adt.ColumnTypeShortInt, adt.ColumnTypeAutoIncrement,
+		adt.ColumnTypeRowVersion, adt.ColumnTypeCurrency:
+		return true
+	}
+	return false
+}
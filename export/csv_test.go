@@ -0,0 +1,87 @@
+package export_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+var csvTable = &adt.Table{Name: "T.ADT", Columns: []*adt.Column{
+	{Name: "ID", Type: adt.ColumnTypeAutoIncrement},
+	{Name: "NAME", Type: adt.ColumnTypeCharacter},
+	{Name: "BORN", Type: adt.ColumnTypeDate},
+	{Name: "AT", Type: adt.ColumnTypeTime},
+	{Name: "MEMO", Type: adt.ColumnTypeMemo},
+}}
+
+var csvRecord = adt.Record{
+	"ID":   uint32(7),
+	"NAME": "Smith, \"Jo\"",
+	"BORN": nil,
+	"AT":   time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond,
+	"MEMO": []byte("caf\xe9"),
+}
+
+func TestCSVWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts export.CSVOptions
+		want string
+	}{
+		{"defaults", export.CSVOptions{Header: true},
+			"ID,NAME,BORN,AT,MEMO\n7,\"Smith, \"\"Jo\"\"\",,01:02:03.004,café\n"},
+		{"nulls and layouts", export.CSVOptions{Format: export.Format{Null: "NULL", TimeLayout: "15:04", Binary: export.BinaryHex}},
+			"7,\"Smith, \"\"Jo\"\"\",NULL,01:02,636166e9\n"},
+		{"nonnumeric quoting", export.CSVOptions{Quote: export.QuoteNonNumeric, Comma: ';', Columns: []string{"name", "id"}},
+			"\"Smith, \"\"Jo\"\"\";7\n"},
+		{"omit binary", export.CSVOptions{Header: true, Format: export.Format{Binary: export.BinaryOmit}, Columns: []string{"ID", "MEMO"}},
+			"ID\n7\n"},
+		{"cp1252", export.CSVOptions{Encoding: export.EncodingCP1252, Columns: []string{"MEMO"}},
+			"caf\xe9\n"},
+		{"bom", export.CSVOptions{Encoding: export.EncodingUTF8BOM, Columns: []string{"ID"}},
+			"\ufeff7\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w, err := export.NewCSVWriter(&buf, csvTable, tt.opts)
+		if err != nil {
+			t.Fatal(tt.name, err)
+		}
+		if err := w.Write(csvRecord); err != nil {
+			t.Fatal(tt.name, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(tt.name, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+	if _, err := export.NewCSVWriter(&bytes.Buffer{}, csvTable, export.CSVOptions{Columns: []string{"NOPE"}}); err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
+
+func TestNewEncoderCP1252(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewEncoder(&buf, export.EncodingCP1252)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bytes 0x80-0x9F of the table, such as the euro sign and curly quotes,
+	// are written back unchanged.
+	in := adt.DecodeString([]byte("\x80 \x93caf\xe9\x94 \x81")) + " \u20ac \u4e16"
+	if _, err := io.WriteString(w, in); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "\x80 \x93caf\xe9\x94 \x81 \x80 \x1a"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Output encodings.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingCP1252  = "cp1252"
+)
+
+// NewEncoder returns a writer converting UTF-8 text written to it into the
+// named encoding on w. It must be closed to flush the final bytes; closing
+// it does not close w.
+func NewEncoder(w io.Writer, name string) (io.WriteCloser, error) {
+	switch strings.ToLower(name) {
+	case "", EncodingUTF8, "utf8":
+		return nopCloser{w}, nil
+	case EncodingUTF8BOM:
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+		return nopCloser{w}, nil
+	case EncodingCP1252, "windows-1252":
+		return transform.NewWriter(w, cp1252Encoder{}), nil
+	}
+	return nil, fmt.Errorf("export: unknown encoding %q", name)
+}
+
+// cp1252Encoder encodes UTF-8 as CP1252. adt.DecodeString maps the bytes
+// 0x80-0x9F to C1 controls, which CP1252 lacks, so those are written back as
+// the bytes they came from, letting the table's bytes pass through.
+// Other characters CP1252 lacks are written as '\x1a'.
+type cp1252Encoder struct{ transform.NopResetter }
+
+func (cp1252Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		b, ok := byte(r), r >= 0x80 && r <= 0x9f
+		if !ok {
+			b, ok = charmap.Windows1252.EncodeRune(r)
+		}
+		if !ok {
+			b = '\x1a'
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,248 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmc/adt"
+)
+
+// Layouts used for JSON dates and timestamps when none are given. ADT values
+// carry no zone, so timestamps are written without one.
+const (
+	JSONDateLayout      = "2006-01-02"
+	JSONTimestampLayout = "2006-01-02T15:04:05.000"
+)
+
+// JSONOptions configures a JSONWriter.
+type JSONOptions struct {
+	// Format renders dates, times and binary values. Null is ignored;
+	// NULL values are always written as null. An empty Binary writes memos
+	// as text and blobs as base64; BinaryOmit leaves their keys out.
+	Format
+	// Array writes a single JSON array instead of one object per line.
+	Array bool
+	// Schema writes an object describing the columns before the records.
+	// In array mode the output becomes a single object instead, holding
+	// the schema and the array: {"schema":{...},"records":[...]}.
+	Schema bool
+	// Indent indents each object with the given string.
+	Indent string
+	// Columns selects and orders the keys written; empty writes all
+	// columns in table order. Names match case-insensitively.
+	Columns []string
+}
+
+// JSONSchema describes a table's columns; it is the value of the "schema"
+// key written with JSONOptions.Schema.
+type JSONSchema struct {
+	Table   string       `json:"table"`
+	Columns []JSONColumn `json:"columns"`
+}
+
+// JSONColumn describes one column in a JSONSchema.
+type JSONColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Length   int    `json:"length"`
+	Decimals int    `json:"decimals,omitempty"`
+	Nullable bool   `json:"nullable"`
+}
+
+// JSONWriter writes records of a table as JSON objects whose keys follow
+// the table's column order.
+type JSONWriter struct {
+	opts    JSONOptions
+	table   *adt.Table
+	columns []*adt.Column
+	w       *bufio.Writer
+	n       int
+}
+
+// NewJSONWriter returns a JSONWriter writing t's records to w. The schema
+// and opening bracket, if any, are written by the first Write or by Close.
+func NewJSONWriter(w io.Writer, t *adt.Table, opts JSONOptions) (*JSONWriter, error) {
+	if !validBinary(opts.Binary) {
+		return nil, fmt.Errorf("export: unknown binary format %q", opts.Binary)
+	}
+	columns, err := SelectColumns(t, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Binary == BinaryOmit {
+		columns = withoutBinary(columns)
+	}
+	opts.DateLayout = layoutOr(opts.DateLayout, JSONDateLayout)
+	opts.TimestampLayout = layoutOr(opts.TimestampLayout, JSONTimestampLayout)
+	return &JSONWriter{opts: opts, table: t, columns: columns, w: bufio.NewWriter(w), n: -1}, nil
+}
+
+// NewJSONSchema returns the JSON description of the given columns of t.
+func NewJSONSchema(t *adt.Table, columns []*adt.Column) JSONSchema {
+	s := JSONSchema{Table: t.Name, Columns: make([]JSONColumn, len(columns))}
+	for i, c := range columns {
+		s.Columns[i] = JSONColumn{
+			Name:     c.Name,
+			Type:     strings.TrimPrefix(c.Type.String(), "ColumnType"),
+			Length:   int(c.Length),
+			Nullable: c.Nullable(),
+		}
+		if c.Type == adt.ColumnTypeCurrency || c.Type == adt.ColumnTypeDouble {
+			s.Columns[i].Decimals = int(c.DecimalDigits)
+		}
+	}
+	return s
+}
+
+// Write writes r as one JSON object.
+func (w *JSONWriter) Write(r adt.Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for _, c := range w.columns {
+		v, err := w.value(c, r[c.Name])
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		name, _ := json.Marshal(c.Name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return w.writeObject(buf.Bytes())
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *JSONWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// Close writes anything still pending, including the closing brackets in
+// array mode, and flushes w. It does not close the underlying writer.
+func (w *JSONWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.opts.Array {
+		if w.n > 0 {
+			w.w.WriteString("\n")
+		}
+		w.w.WriteString("]")
+		if w.opts.Schema {
+			w.w.WriteString("}")
+		}
+		w.w.WriteString("\n")
+	}
+	return w.w.Flush()
+}
+
+// start writes the opening bracket and schema before the first record.
+func (w *JSONWriter) start() error {
+	if w.n >= 0 {
+		return nil
+	}
+	w.n = 0
+	if !w.opts.Schema {
+		if w.opts.Array {
+			w.w.WriteString("[")
+		}
+		return nil
+	}
+	schema, err := json.Marshal(NewJSONSchema(w.table, w.columns))
+	if err != nil {
+		return err
+	}
+	if !w.opts.Array {
+		return w.writeObject(append(append([]byte(`{"schema":`), schema...), '}'))
+	}
+	if w.opts.Indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, schema, "", w.opts.Indent); err != nil {
+			return err
+		}
+		schema = buf.Bytes()
+	}
+	w.w.WriteString(`{"schema":`)
+	w.w.Write(schema)
+	_, err = w.w.WriteString(`,"records":[`)
+	return err
+}
+
+func (w *JSONWriter) writeObject(obj []byte) error {
+	if w.opts.Indent != "" {
+		var buf bytes.Buffer
+		prefix := ""
+		if w.opts.Array {
+			prefix = w.opts.Indent
+		}
+		if err := json.Indent(&buf, obj, prefix, w.opts.Indent); err != nil {
+			return err
+		}
+		obj = buf.Bytes()
+	}
+	if w.opts.Array {
+		if w.n > 0 {
+			w.w.WriteString(",")
+		}
+		w.w.WriteString("\n")
+		if w.opts.Indent != "" {
+			w.w.WriteString(w.opts.Indent)
+		}
+		w.n++
+		_, err := w.w.Write(obj)
+		return err
+	}
+	w.n++
+	w.w.Write(obj)
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+// value returns the JSON encoding of v read from c, or nil if the key should
+// be left out.
+func (w *JSONWriter) value(c *adt.Column, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return []byte("null"), nil
+	case []byte:
+		f := w.opts.Format
+		if f.Binary == "" && c.Type == adt.ColumnTypeBlob {
+			f.Binary = BinaryBase64
+		}
+		s, ok := f.Value(c, v)
+		if !ok {
+			return nil, nil
+		}
+		return json.Marshal(s)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return []byte("null"), nil
+		}
+		if c.Type == adt.ColumnTypeCurrency {
+			return json.Marshal(strconv.FormatFloat(v, 'f', c.CurrencyScale(), 64))
+		}
+		return json.Marshal(v)
+	case time.Time, time.Duration:
+		s, _ := w.opts.Value(c, v)
+		return json.Marshal(s)
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,57 @@
+package export_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+func TestJSONWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		opts export.JSONOptions
+		want string
+	}{
+		{"ndjson", export.JSONOptions{},
+			`{"ID":7,"NAME":"Smith, \"Jo\"","BORN":null,"AT":"01:02:03.004","MEMO":"café"}` + "\n"},
+		{"array with schema", export.JSONOptions{Array: true, Schema: true, Columns: []string{"id"}},
+			`{"schema":{"table":"T.ADT","columns":[{"name":"ID","type":"AutoIncrement","length":0,"nullable":false}]},"records":[` +
+				"\n" + `{"ID":7}` + "\n]}\n"},
+		{"lines with schema", export.JSONOptions{Schema: true, Columns: []string{"id"}},
+			`{"schema":{"table":"T.ADT","columns":[{"name":"ID","type":"AutoIncrement","length":0,"nullable":false}]}}` + "\n" + `{"ID":7}` + "\n"},
+		{"array", export.JSONOptions{Array: true, Columns: []string{"id"}},
+			"[\n" + `{"ID":7}` + "\n]\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w, err := export.NewJSONWriter(&buf, csvTable, tt.opts)
+		if err != nil {
+			t.Fatal(tt.name, err)
+		}
+		if err := w.Write(csvRecord); err != nil {
+			t.Fatal(tt.name, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(tt.name, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJSONWriterCurrency(t *testing.T) {
+	table := &adt.Table{Columns: []*adt.Column{{Name: "BAL", Type: adt.ColumnTypeCurrency, DecimalDigits: 2}}}
+	var buf bytes.Buffer
+	w, err := export.NewJSONWriter(&buf, table, export.JSONOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(adt.Record{"BAL": 12.5})
+	w.Close()
+	if got, want := buf.String(), `{"BAL":"12.50"}`+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+// Package export writes ADT tables in formats used outside of Advantage.
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tmc/adt"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// ParquetOptions configures WriteParquet.
+type ParquetOptions struct {
+	// RowGroupSize is the approximate size in bytes of each row group.
+	// Zero uses DefaultRowGroupSize.
+	RowGroupSize int64
+	// PageSize is the approximate size in bytes of each data page. Zero
+	// uses the writer's default of 8KB.
+	PageSize int64
+	// Compression names the codec: uncompressed, snappy, gzip, lz4 or zstd.
+	// Empty uses snappy.
+	Compression string
+	// SkipDeleted leaves out records flagged as deleted.
+	SkipDeleted bool
+	// Optional names columns declared OPTIONAL even if they can't hold
+	// NULL, such as columns whose values are masked.
+	Optional []string
+}
+
+// DefaultRowGroupSize is the row group size used when none is given.
+const DefaultRowGroupSize = 128 * 1024 * 1024
+
+// Parquet currency columns are DECIMAL(adt.CurrencyPrecision, scale). INT64
+// only holds 18 digits, so they are stored as big-endian two's complement
+// integers of currencyLength bytes.
+const currencyLength = 9
+
+// ParquetSchema returns the parquet-go metadata describing each column of t.
+// Columns that can't hold NULL are REQUIRED, unless named in optional.
+func ParquetSchema(t *adt.Table, optional ...string) ([]string, error) {
+	md := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		typ, err := parquetType(c)
+		if err != nil {
+			return nil, err
+		}
+		repetition := "REQUIRED"
+		if c.Nullable() || containsFold(optional, c.Name) {
+			repetition = "OPTIONAL"
+		}
+		md = append(md, fmt.Sprintf("name=%s, %s, repetitiontype=%s", c.Name, typ, repetition))
+	}
+	return md, nil
+}
+
+func parquetType(c *adt.Column) (string, error) {
+	switch c.Type {
+	case adt.ColumnTypeCharacter, adt.ColumnTypeCiCharacter, adt.ColumnTypeMemo:
+		return "type=BYTE_ARRAY, convertedtype=UTF8", nil
+	case adt.ColumnTypeBlob:
+		return "type=BYTE_ARRAY", nil
+	case adt.ColumnTypeBool:
+		return "type=BOOLEAN", nil
+	case adt.ColumnTypeDouble:
+		return "type=DOUBLE", nil
+	case adt.ColumnTypeInt:
+		return "type=INT32", nil
+	case adt.ColumnTypeShortInt:
+		return "type=INT32, convertedtype=INT_16", nil
+	case adt.ColumnTypeAutoIncrement:
+		return "type=INT64", nil
+	case adt.ColumnTypeRowVersion:
+		return "type=INT64, convertedtype=UINT_64", nil
+	case adt.ColumnTypeDate:
+		return "type=INT32, convertedtype=DATE", nil
+	case adt.ColumnTypeTime:
+		return "type=INT32, convertedtype=TIME_MILLIS", nil
+	case adt.ColumnTypeTimestamp, adt.ColumnTypeModTime:
+		return "type=INT64, convertedtype=TIMESTAMP_MILLIS", nil
+	case adt.ColumnTypeCurrency:
+		return fmt.Sprintf("type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, precision=%d, scale=%d, length=%d",
+			adt.CurrencyPrecision, c.CurrencyScale(), currencyLength), nil
+	}
+	return "", fmt.Errorf("export: no parquet type for column %s", c)
+}
+
+// containsFold reports whether names holds name, ignoring case.
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParquetWriter writes records of a table to a Parquet file. Row groups are
+// flushed as they fill, so memory use is bounded by the row group size
+// rather than the table size.
+type ParquetWriter struct {
+	table    *adt.Table
+	required []bool // by column, whether the schema declares it REQUIRED
+	pw       *writer.CSVWriter
+	n        int
+}
+
+// NewParquetWriter returns a ParquetWriter writing t's records to w.
+func NewParquetWriter(w io.Writer, t *adt.Table, opts ParquetOptions) (*ParquetWriter, error) {
+	md, err := ParquetSchema(t, opts.Optional...)
+	if err != nil {
+		return nil, err
+	}
+	required := make([]bool, len(t.Columns))
+	for i, m := range md {
+		required[i] = strings.HasSuffix(m, "repetitiontype=REQUIRED")
+	}
+	codec, err := compressionCodec(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.CompressionType = codec
+	pw.RowGroupSize = DefaultRowGroupSize
+	if opts.RowGroupSize > 0 {
+		pw.RowGroupSize = opts.RowGroupSize
+	}
+	if opts.PageSize > 0 {
+		pw.PageSize = opts.PageSize
+	}
+	return &ParquetWriter{table: t, required: required, pw: pw}, nil
+}
+
+// Write adds r to the current row group. A NULL in a REQUIRED column is an
+// error.
+func (w *ParquetWriter) Write(r adt.Record) error {
+	row := make([]interface{}, len(w.table.Columns))
+	for i, c := range w.table.Columns {
+		v, err := parquetValue(c, r[c.Name])
+		if err != nil {
+			return err
+		}
+		if v == nil && w.required[i] {
+			return fmt.Errorf("export: NULL in required parquet column %s", c.Name)
+		}
+		row[i] = v
+	}
+	w.n++
+	return w.pw.Write(row)
+}
+
+// Rows returns the number of rows written.
+func (w *ParquetWriter) Rows() int { return w.n }
+
+// Close flushes the last row group and writes the file footer. It does not
+// close the underlying writer.
+func (w *ParquetWriter) Close() error {
+	return w.pw.WriteStop()
+}
+
+// WriteParquet streams every record of t to w as a Parquet file and returns
+// the number of rows written.
+func WriteParquet(w io.Writer, t *adt.Table, opts ParquetOptions) (int, error) {
+	pw, err := NewParquetWriter(w, t, opts)
+	if err != nil {
+		return 0, err
+	}
+	records := All
+	if opts.SkipDeleted {
+		records.Deleted = DeletedSkip
+	}
+	if err := records.Each(t, func(i int, r adt.Record) error {
+		return pw.Write(r)
+	}); err != nil {
+		return pw.Rows(), err
+	}
+	return pw.Rows(), pw.Close()
+}
+
+func compressionCodec(name string) (parquet.CompressionCodec, error) {
+	if name == "" {
+		return parquet.CompressionCodec_SNAPPY, nil
+	}
+	if strings.EqualFold(name, "none") {
+		return parquet.CompressionCodec_UNCOMPRESSED, nil
+	}
+	codec, err := parquet.CompressionCodecFromString(strings.ToUpper(name))
+	if err != nil {
+		return codec, fmt.Errorf("export: unknown compression codec %q", name)
+	}
+	return codec, nil
+}
+
+// parquetValue converts a value read from c into the Go type parquet-go
+// expects for the column's physical type.
+func parquetValue(c *adt.Column, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		if c.Type == adt.ColumnTypeMemo {
+			return adt.DecodeString(v), nil
+		}
+		return string(v), nil
+	case bool:
+		return v, nil
+	case int16:
+		return int32(v), nil
+	case int32:
+		return v, nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case time.Duration:
+		return int32(v / time.Millisecond), nil
+	case time.Time:
+		// ADT values carry no zone; keep the wall clock as written.
+		wall := time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+		if c.Type == adt.ColumnTypeDate {
+			return int32(wall.Unix() / 86400), nil
+		}
+		return wall.UnixNano() / int64(time.Millisecond), nil
+	case float64:
+		if c.Type == adt.ColumnTypeCurrency {
+			return currencyBytes(int64(math.Round(v * math.Pow10(c.CurrencyScale())))), nil
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("export: unexpected %T for column %s", v, c)
+}
+
+// currencyBytes returns the unscaled value of a currency as the
+// currencyLength bytes parquet-go expects for a FIXED_LEN_BYTE_ARRAY.
+func currencyBytes(n int64) string {
+	b := make([]byte, currencyLength)
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return string(b)
+}
@@ -0,0 +1,129 @@
+package export_test
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestParquetSchema(t *testing.T) {
+	table := &adt.Table{Columns: []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Length: 4},
+		{Name: "BORN", Type: adt.ColumnTypeDate, Length: 4},
+		{Name: "BALANCE", Type: adt.ColumnTypeCurrency, Length: 8, DecimalDigits: 2},
+		{Name: "NOTES", Type: adt.ColumnTypeMemo, Length: 9},
+	}}
+	got, err := export.ParquetSchema(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"name=ID, type=INT64, repetitiontype=REQUIRED",
+		"name=BORN, type=INT32, convertedtype=DATE, repetitiontype=OPTIONAL",
+		"name=BALANCE, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, precision=19, scale=2, length=9, repetitiontype=OPTIONAL",
+		"name=NOTES, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParquetSchema = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParquetSchema[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	got, err = export.ParquetSchema(table, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "name=NOTES, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"; got[3] != want {
+		t.Errorf("ParquetSchema with NOTES optional: %q, want %q", got[3], want)
+	}
+}
+
+func TestParquetWriterRequired(t *testing.T) {
+	table := &adt.Table{Columns: []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Length: 4},
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Length: 10},
+	}}
+	masked := adt.Record{"ID": uint32(1), "NAME": nil}
+	w, err := export.NewParquetWriter(io.Discard, table, export.ParquetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(masked); err == nil {
+		t.Error("NULL in a REQUIRED column written")
+	}
+	w, err = export.NewParquetWriter(io.Discard, table, export.ParquetOptions{Optional: []string{"NAME"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(masked); err != nil {
+		t.Error(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestParquetCurrency writes currency values and reads them back as the
+// unscaled integers of DECIMAL(19, 2).
+func TestParquetCurrency(t *testing.T) {
+	table := &adt.Table{Columns: []*adt.Column{
+		{Name: "BALANCE", Type: adt.ColumnTypeCurrency, Length: 8, DecimalDigits: 2},
+	}}
+	values := []float64{1234.56, -7.5, 0, 12345678901.23}
+	want := []int64{123456, -750, 0, 1234567890123}
+
+	var buf bytes.Buffer
+	w, err := export.NewParquetWriter(&buf, table, export.ParquetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range values {
+		if err := w.Write(adt.Record{"BALANCE": v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetReader(f, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+	rows, err := pr.ReadByNumber(int(pr.GetNumRows()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("read %d rows, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		field := reflect.ValueOf(row).FieldByName("BALANCE")
+		if field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		// FIXED_LEN_BYTE_ARRAY decimals are big-endian two's complement.
+		b := []byte(field.String())
+		n := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+		}
+		if !n.IsInt64() || n.Int64() != want[i] {
+			t.Errorf("row %d: read %v, want %d", i, n, want[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/tmc/adt"
+)
+
+// Policies for records flagged as deleted.
+const (
+	DeletedInclude = "include" // read deleted records like any other
+	DeletedSkip    = "skip"    // leave deleted records out
+	DeletedOnly    = "only"    // read only deleted records
+)
+
+// Range selects the records of a table to read.
+type Range struct {
+	// Start is the index of the first record.
+	Start int
+	// Count is the number of records to read from Start; negative reads
+	// to the end of the table.
+	Count int
+	// Deleted is one of the Deleted constants; empty is DeletedInclude.
+	Deleted string
+}
+
+// All selects every record of a table.
+var All = Range{Count: -1}
+
+// Each calls fn with the index and contents of each record of t selected by
+// r, stopping at the first error.
+func (r Range) Each(t *adt.Table, fn func(i int, rec adt.Record) error) error {
+	switch r.Deleted {
+	case "", DeletedInclude, DeletedSkip, DeletedOnly:
+	default:
+		return fmt.Errorf("export: unknown deleted record policy %q", r.Deleted)
+	}
+	end := int(t.RecordCount)
+	if r.Count >= 0 && r.Start+r.Count < end {
+		end = r.Start + r.Count
+	}
+	for i := r.Start; i < end; i++ {
+		if r.Deleted == DeletedSkip || r.Deleted == DeletedOnly {
+			deleted, err := t.IsDeleted(i)
+			if err != nil {
+				return err
+			}
+			if deleted != (r.Deleted == DeletedOnly) {
+				continue
+			}
+		}
+		rec, err := t.Get(i)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i, err)
+		}
+		if err := fn(i, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Project returns a view of t reading only the named columns, in that order.
+// The view shares t's files; an empty list returns t itself.
+func Project(t *adt.Table, columns []string) (*adt.Table, error) {
+	if len(columns) == 0 {
+		return t, nil
+	}
+	selected, err := SelectColumns(t, columns)
+	if err != nil {
+		return nil, err
+	}
+	view := *t
+	view.Columns = selected
+	return &view, nil
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/adt"
+)
+
+// MaxSQLBatch is the most rows SQL Server accepts in one VALUES list, and so
+// the largest batch a portable script uses.
+const MaxSQLBatch = 1000
+
+// SQLOptions configures an SQLWriter.
+type SQLOptions struct {
+	// Dialect is the SQL dialect of the script.
+	Dialect adt.Dialect
+	// Table names the target table; empty uses the table's own name
+	// without its extension.
+	Table string
+	// Batch is the number of rows per INSERT statement, at most
+	// MaxSQLBatch. Zero uses 100.
+	Batch int
+	// Indexes writes the table's index DDL after its rows.
+	Indexes bool
+}
+
+// beginStatements start a transaction in each dialect.
+var beginStatements = map[string]string{
+	"mysql":     "START TRANSACTION;",
+	"postgres":  "BEGIN;",
+	"sqlite":    "BEGIN;",
+	"sqlserver": "BEGIN TRANSACTION;",
+}
+
+// SQLWriter writes a table as a SQL script: its DDL, then INSERT statements
+// of literal values inside a transaction, then optionally its indexes.
+// AutoIncrement columns keep their values from the table.
+type SQLWriter struct {
+	opts   SQLOptions
+	table  *adt.Table
+	w      *bufio.Writer
+	insert string
+	values []string
+	n      int
+}
+
+// NewSQLWriter writes the DDL for t to w and returns an SQLWriter for its
+// rows.
+func NewSQLWriter(w io.Writer, t *adt.Table, opts SQLOptions) (*SQLWriter, error) {
+	if opts.Dialect == nil {
+		opts.Dialect = adt.MySQL
+	}
+	if opts.Table == "" {
+		opts.Table = strings.TrimSuffix(strings.TrimSuffix(t.Name, ".ADT"), ".adt")
+	}
+	if opts.Batch <= 0 {
+		opts.Batch = 100
+	}
+	if opts.Batch > MaxSQLBatch {
+		opts.Batch = MaxSQLBatch
+	}
+	d := opts.Dialect
+	ddl, err := t.DialectDDL(d, opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	quoted := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		quoted[i] = d.QuoteIdent(c.Name)
+	}
+	sw := &SQLWriter{
+		opts:   opts,
+		table:  t,
+		w:      bufio.NewWriter(w),
+		insert: "INSERT INTO " + d.QuoteIdent(opts.Table) + " (" + strings.Join(quoted, ",") + ") VALUES",
+		values: make([]string, len(t.Columns)),
+	}
+	fmt.Fprintf(sw.w, "-- %s (%d records)\n%s;\n\n", t.Name, t.RecordCount, ddl)
+	fmt.Fprintln(sw.w, beginStatements[d.Name()])
+	if stmt := t.IdentityInsertSQL(d, opts.Table, true); stmt != "" {
+		fmt.Fprintf(sw.w, "%s;\n", stmt)
+	}
+	return sw, nil
+}
+
+// Write adds r to the current INSERT statement, starting a new statement
+// every Batch rows.
+func (w *SQLWriter) Write(r adt.Record) error {
+	for i, c := range w.table.Columns {
+		w.values[i] = w.opts.Dialect.Literal(c, r[c.Name])
+	}
+	sep := ","
+	if w.n%w.opts.Batch == 0 {
+		if w.n > 0 {
+			w.w.WriteString(";\n")
+		}
+		w.w.WriteString(w.insert)
+		sep = ""
+	}
+	w.n++
+	_, err := w.w.WriteString(sep + "\n(" + strings.Join(w.values, ",") + ")")
+	return err
+}
+
+// Rows returns the number of rows written.
+func (w *SQLWriter) Rows() int { return w.n }
+
+// Close ends the last statement and the transaction, writes the index DDL
+// if requested and flushes w. It does not close the underlying writer.
+// Before committing, the script resets the sequences of AutoIncrement
+// columns past the keys it inserted.
+func (w *SQLWriter) Close() error {
+	if w.n > 0 {
+		w.w.WriteString(";\n")
+	}
+	d := w.opts.Dialect
+	if stmt := w.table.IdentityInsertSQL(d, w.opts.Table, false); stmt != "" {
+		fmt.Fprintf(w.w, "%s;\n", stmt)
+	}
+	for _, stmt := range w.table.ResetSequenceSQL(d, w.opts.Table) {
+		fmt.Fprintf(w.w, "%s;\n", stmt)
+	}
+	fmt.Fprintf(w.w, "COMMIT;\n\n")
+	if w.opts.Indexes {
+		for _, stmt := range w.table.IndexDDL(w.opts.Dialect, w.opts.Table) {
+			fmt.Fprintf(w.w, "%s;\n", stmt)
+		}
+		fmt.Fprintln(w.w)
+	}
+	return w.w.Flush()
+}
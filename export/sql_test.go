@@ -0,0 +1,46 @@
+package export_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+func TestSQLWriterIdentity(t *testing.T) {
+	table := &adt.Table{Name: "T.ADT", RecordCount: 1, Columns: []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeAutoIncrement},
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Length: 10},
+	}}
+	tests := []struct {
+		dialect adt.Dialect
+		want    string
+	}{
+		{adt.SQLServer, "BEGIN TRANSACTION;\nSET IDENTITY_INSERT [T] ON;\nINSERT INTO [T] ([ID],[NAME]) VALUES\n(7,N'Jo');\nSET IDENTITY_INSERT [T] OFF;\nCOMMIT;\n\n"},
+		{adt.PostgreSQL, "BEGIN;\nINSERT INTO \"T\" (\"ID\",\"NAME\") VALUES\n(7,'Jo');\n" +
+			"SELECT setval(pg_get_serial_sequence('\"T\"', 'ID'), COALESCE(MAX(\"ID\"), 0) + 1, false) FROM \"T\";\nCOMMIT;\n\n"},
+		{adt.MySQL, "START TRANSACTION;\nINSERT INTO `T` (`ID`,`NAME`) VALUES\n(7,'Jo');\nCOMMIT;\n\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w, err := export.NewSQLWriter(&buf, table, export.SQLOptions{Dialect: tt.dialect})
+		if err != nil {
+			t.Fatal(tt.dialect.Name(), err)
+		}
+		if err := w.Write(adt.Record{"ID": uint32(7), "NAME": "Jo"}); err != nil {
+			t.Fatal(tt.dialect.Name(), err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(tt.dialect.Name(), err)
+		}
+		got := buf.String()
+		// Skip the comment and DDL, which the dialect tests cover.
+		if i := bytes.Index(buf.Bytes(), []byte(";\n\n")); i >= 0 {
+			got = got[i+3:]
+		}
+		if got != tt.want {
+			t.Errorf("%s script:\ngot  %q\nwant %q", tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package adt
+
+// Index describes an index tag on a table.
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+// primaryKey returns the names of the columns making up the table's primary
+// key: the columns of a primary index if one is defined, otherwise the
+// AutoIncrement column.
+func (t *Table) primaryKey() ([]string, error) {
+	for _, idx := range t.Indexes {
+		if idx.Primary {
+			return idx.Columns, nil
+		}
+	}
+	pk, err := t.GetPK()
+	if err != nil || pk == nil {
+		return nil, err
+	}
+	return []string{pk.Name}, nil
+}
+
+// uniqueIndexes returns the unique, non-primary indexes of the table, plus
+// one for each AutoIncrement column that no primary or unique index leads:
+// MySQL rejects an AUTO_INCREMENT column that isn't the first column of a
+// key, which happens when the primary index is on some other column.
+func (t *Table) uniqueIndexes() []*Index {
+	var result []*Index
+	leads := make(map[string]bool)
+	for _, idx := range t.Indexes {
+		if idx.Unique && !idx.Primary {
+			result = append(result, idx)
+		}
+		if (idx.Unique || idx.Primary) && len(idx.Columns) > 0 {
+			leads[idx.Columns[0]] = true
+		}
+	}
+	pk, _ := t.primaryKey()
+	if len(pk) > 0 {
+		leads[pk[0]] = true
+	}
+	for _, c := range t.Columns {
+		if c.Type == ColumnTypeAutoIncrement && !leads[c.Name] {
+			result = append(result, &Index{Columns: []string{c.Name}, Unique: true})
+		}
+	}
+	return result
+}
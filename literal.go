@@ -0,0 +1,107 @@
+package adt
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// literalStyle describes how a dialect spells SQL literals.
+type literalStyle struct {
+	// escapeBackslash doubles backslashes in string literals.
+	escapeBackslash bool
+	// nationalStrings prefixes string literals with N.
+	nationalStrings bool
+	// boolTrue and boolFalse are the boolean literals.
+	boolTrue, boolFalse string
+	// bytes returns the literal for binary data.
+	bytes func(b []byte) string
+}
+
+var (
+	mysqlLiterals = literalStyle{
+		escapeBackslash: true,
+		boolTrue:        "TRUE",
+		boolFalse:       "FALSE",
+		bytes:           func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+	}
+	postgresLiterals = literalStyle{
+		boolTrue:  "TRUE",
+		boolFalse: "FALSE",
+		bytes:     func(b []byte) string { return `'\x` + hex.EncodeToString(b) + "'::bytea" },
+	}
+	sqliteLiterals = literalStyle{
+		boolTrue:  "1",
+		boolFalse: "0",
+		bytes:     func(b []byte) string { return "X'" + hex.EncodeToString(b) + "'" },
+	}
+	sqlserverLiterals = literalStyle{
+		nationalStrings: true,
+		boolTrue:        "1",
+		boolFalse:       "0",
+		bytes:           func(b []byte) string { return "0x" + hex.EncodeToString(b) },
+	}
+)
+
+// FormatDuration formats a Time column value as HH:MM:SS.mmm.
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	ms := int(d / time.Millisecond % 1000)
+	sec := int(d / time.Second % 60)
+	min := int(d / time.Minute % 60)
+	hour := int(d / time.Hour)
+	return fmt.Sprintf("%s%02d:%02d:%02d.%03d", sign, hour, min, sec, ms)
+}
+
+func (s literalStyle) str(v string) string {
+	// NUL can't be stored in text columns by most databases.
+	v = strings.Replace(v, "\x00", "", -1)
+	if s.escapeBackslash {
+		v = strings.Replace(v, `\`, `\\`, -1)
+	}
+	v = "'" + strings.Replace(v, "'", "''", -1) + "'"
+	if s.nationalStrings {
+		v = "N" + v
+	}
+	return v
+}
+
+// literal returns v, a value read from column c, as a SQL literal.
+func (s literalStyle) literal(c *Column, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return s.boolTrue
+		}
+		return s.boolFalse
+	case string:
+		return s.str(v)
+	case []byte:
+		if c.Type == ColumnTypeMemo {
+			return s.str(DecodeString(v))
+		}
+		return s.bytes(v)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "NULL"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		if c.Type == ColumnTypeDate {
+			return "'" + v.Format("2006-01-02") + "'"
+		}
+		return "'" + v.Format("2006-01-02 15:04:05.000") + "'"
+	case time.Duration:
+		return "'" + FormatDuration(v) + "'"
+	}
+	return fmt.Sprint(v)
+}
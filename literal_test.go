@@ -0,0 +1,43 @@
+package adt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tmc/adt"
+)
+
+func TestLiteral(t *testing.T) {
+	char := &adt.Column{Type: adt.ColumnTypeCharacter}
+	memo := &adt.Column{Type: adt.ColumnTypeMemo}
+	blob := &adt.Column{Type: adt.ColumnTypeBlob}
+	date := &adt.Column{Type: adt.ColumnTypeDate}
+	stamp := &adt.Column{Type: adt.ColumnTypeTimestamp}
+	when := time.Date(2017, time.October, 17, 13, 4, 5, 6e6, time.UTC)
+	tests := []struct {
+		dialect adt.Dialect
+		column  *adt.Column
+		value   interface{}
+		want    string
+	}{
+		{adt.MySQL, char, nil, "NULL"},
+		{adt.MySQL, char, `it's C:\`, `'it''s C:\\'`},
+		{adt.PostgreSQL, char, `it's C:\`, `'it''s C:\'`},
+		{adt.SQLServer, char, "café", "N'café'"},
+		{adt.MySQL, memo, []byte("caf\xe9"), "'café'"},
+		{adt.MySQL, blob, []byte{0xde, 0xad}, "X'dead'"},
+		{adt.PostgreSQL, blob, []byte{0xde, 0xad}, `'\xdead'::bytea`},
+		{adt.SQLServer, blob, []byte{0xde, 0xad}, "0xdead"},
+		{adt.PostgreSQL, date, when, "'2017-10-17'"},
+		{adt.PostgreSQL, stamp, when, "'2017-10-17 13:04:05.006'"},
+		{adt.SQLite, char, true, "1"},
+		{adt.MySQL, char, 90*time.Minute + 1500*time.Millisecond, "'01:30:01.500'"},
+		{adt.MySQL, char, 1.25, "1.25"},
+		{adt.MySQL, char, int32(-7), "-7"},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.Literal(tt.column, tt.value); got != tt.want {
+			t.Errorf("%s Literal(%v) = %s, want %s", tt.dialect.Name(), tt.value, got, tt.want)
+		}
+	}
+}
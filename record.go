@@ -2,4 +2,7 @@ package adt
 
 const RecordMagicHeader = "\x04\x00\x00\x00"
 
+// RecordFlagDeleted is the value of the first byte of a deleted record.
+const RecordFlagDeleted = 0x05
+
 type Record map[string]interface{}
@@ -0,0 +1,195 @@
+package adt
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrShortRecord is recorded for fields of a partial trailing record that
+// lie past the end of the file.
+var ErrShortRecord = errors.New("adt: field past the end of the file")
+
+// SalvagedRecord is a record recovered by Salvage.
+type SalvagedRecord struct {
+	// Index is the record's position in the file.
+	Index   int
+	Deleted bool
+	// Partial is set for a trailing record cut short by the end of the file.
+	Partial bool
+	// Record holds the values that could be read. Fields listed in Errors
+	// are nil.
+	Record Record
+	// Errors holds, by column name, the error reading each unreadable field.
+	Errors map[string]error
+	raw    []byte
+}
+
+// OK reports whether every field of the record was read.
+func (r *SalvagedRecord) OK() bool { return !r.Partial && len(r.Errors) == 0 }
+
+// SalvageCount returns the number of records in the file judging by its
+// size rather than the header, counting a partial trailing record.
+func (t *Table) SalvageCount() (int, error) {
+	if t.RecordLength == 0 {
+		return 0, nil
+	}
+	size, err := t.data.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if size <= int64(t.DataOffset) {
+		return 0, nil
+	}
+	n := size - int64(t.DataOffset)
+	return int((n + int64(t.RecordLength) - 1) / int64(t.RecordLength)), nil
+}
+
+// Salvage calls fn with every record that can be recovered from a damaged
+// table. Unlike Get it ignores RecordCount in favour of the file size,
+// returns the leading fields of a partial trailing record and reads past
+// unreadable memo blocks, noting each field it could not read in the
+// record's Errors. It stops early only if fn returns an error or the file
+// can't be read.
+func (t *Table) Salvage(fn func(r *SalvagedRecord) error) error {
+	count, err := t.SalvageCount()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < count; start += scanBatch {
+		n := count - start
+		if n > scanBatch {
+			n = scanBatch
+		}
+		raw, err := t.salvageRaw(start, n)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n && len(raw) > 0; i++ {
+			end := int(t.RecordLength)
+			if end > len(raw) {
+				end = len(raw)
+			}
+			if err := fn(t.salvageRecord(start+i, raw[:end])); err != nil {
+				return err
+			}
+			raw = raw[end:]
+		}
+	}
+	return nil
+}
+
+// salvageRaw is readRaw tolerating a short read at the end of the file.
+func (t *Table) salvageRaw(record, n int) ([]byte, error) {
+	if _, err := t.data.Seek(int64(t.DataOffset)+int64(t.RecordLength)*int64(record), io.SeekStart); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, n*int(t.RecordLength))
+	read, err := io.ReadFull(t.data, raw)
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return raw[:read], err
+}
+
+func (t *Table) salvageRecord(index int, raw []byte) *SalvagedRecord {
+	r := &SalvagedRecord{
+		Index:   index,
+		Deleted: raw[0] == RecordFlagDeleted,
+		Partial: len(raw) < int(t.RecordLength),
+		Record:  Record{},
+		Errors:  map[string]error{},
+		raw:     raw,
+	}
+	for _, c := range t.Columns {
+		r.Record[c.Name] = nil
+		if int(c.Offset)+int(c.Length) > len(raw) {
+			r.Errors[c.Name] = ErrShortRecord
+			continue
+		}
+		value, err := ReadValue(raw, c)
+		if m, ok := value.(MemoField); ok && err == nil {
+			value, err = t.readMemo(m)
+		}
+		if err != nil {
+			r.Errors[c.Name] = err
+			continue
+		}
+		r.Record[c.Name] = value
+	}
+	return r
+}
+
+// SalvageWriter writes salvaged records to a new .ADT file with the
+// columns of the damaged table and a record count matching its contents.
+// Memo fields keep pointing into the original .ADM file, which should be
+// copied alongside with CopyMemo; pointers to memos that could not be read
+// are cleared.
+type SalvageWriter struct {
+	w     io.WriteSeeker
+	table *Table
+	n     uint32
+}
+
+// NewSalvageWriter writes the header and column descriptors of t to w.
+func NewSalvageWriter(w io.WriteSeeker, t *Table) (*SalvageWriter, error) {
+	if _, err := t.data.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	header := make([]byte, t.DataOffset)
+	if _, err := io.ReadFull(t.data, header); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(header[24:], 0)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &SalvageWriter{w: w, table: t}, nil
+}
+
+// Write appends r. The missing tail of a partial record is zero filled.
+func (w *SalvageWriter) Write(r *SalvagedRecord) error {
+	rec := make([]byte, w.table.RecordLength)
+	copy(rec, r.raw)
+	for _, c := range w.table.Columns {
+		if r.Errors[c.Name] != nil && c.Type == ColumnTypeMemo && int(c.Offset)+int(c.Length) <= len(rec) {
+			for i := c.Offset; i < c.Offset+c.Length; i++ {
+				rec[i] = 0
+			}
+		}
+	}
+	if _, err := w.w.Write(rec); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// Records returns the number of records written.
+func (w *SalvageWriter) Records() int { return int(w.n) }
+
+// Close sets the record count in the header. It does not close the
+// underlying writer.
+func (w *SalvageWriter) Close() error {
+	if _, err := w.w.Seek(24, io.SeekStart); err != nil {
+		return err
+	}
+	if err := binary.Write(w.w, binary.LittleEndian, w.n); err != nil {
+		return err
+	}
+	_, err := w.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// CopyMemo copies the table's .ADM file to w, for a copy of the table whose
+// memo fields keep their block numbers. It returns ErrNoMemoFile if the
+// table has none.
+func (t *Table) CopyMemo(w io.Writer) (int64, error) {
+	if t.memoData == nil {
+		return 0, ErrNoMemoFile
+	}
+	if _, err := t.memoData.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(w, t.memoData)
+}
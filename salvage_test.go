@@ -0,0 +1,168 @@
+package adt_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/adt"
+)
+
+func TestSalvage(t *testing.T) {
+	columns := []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeInt, Offset: 5, Length: 4},
+		{Name: "NOTE", Type: adt.ColumnTypeMemo, Offset: 9, Length: 9},
+		{Name: "N", Type: adt.ColumnTypeInt, Offset: 18, Length: 4},
+	}
+	record := func(id int32, block uint32, length uint16, n int32) []byte {
+		b := append(le32(id), le32(int32(block))...)
+		b = append(b, byte(length), byte(length>>8), 0, 0, 0)
+		return append(b, le32(n)...)
+	}
+	data := adtBytes(columns, [][]byte{
+		record(1, 1, 3, 10),
+		record(2, 9, 3, 20), // memo past the end of the .ADM
+		record(3, 0, 0, 30),
+		record(4, 1, 3, 40),
+	})
+	// Claim 9 records and cut the last one short in its N field.
+	data[24] = 9
+	data = data[:len(data)-2]
+	memo := []byte("........abc")
+
+	table, err := adt.FromReaders(bytes.NewReader(data), bytes.NewReader(memo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := table.SalvageCount(); err != nil || n != 4 {
+		t.Fatalf("SalvageCount() = %d, %v; want 4", n, err)
+	}
+	var got []*adt.SalvagedRecord
+	if err := table.Salvage(func(r *adt.SalvagedRecord) error {
+		got = append(got, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("salvaged %d records, want 4", len(got))
+	}
+	if !got[0].OK() || string(got[0].Record["NOTE"].([]byte)) != "abc" {
+		t.Errorf("record 0 = %v, %v", got[0].Record, got[0].Errors)
+	}
+	if got[1].Errors["NOTE"] == nil || got[1].Record["ID"] != int32(2) || got[1].Record["N"] != int32(20) {
+		t.Errorf("record 1 = %v, %v; want an unreadable memo only", got[1].Record, got[1].Errors)
+	}
+	if last := got[3]; !last.Partial || last.Errors["N"] != adt.ErrShortRecord || last.Record["ID"] != int32(4) {
+		t.Errorf("record 3 = %+v; want partial with N short", last)
+	}
+
+	// Write the complete records to a clean table.
+	path := filepath.Join(t.TempDir(), "CLEAN.ADT")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := adt.NewSalvageWriter(f, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range got[:3] {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	m, err := os.Create(strings.TrimSuffix(path, ".ADT") + ".ADM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := table.CopyMemo(m); err != nil || n != int64(len(memo)) {
+		t.Fatalf("CopyMemo() = %d, %v; want %d", n, err, len(memo))
+	}
+	m.Close()
+
+	clean, err := adt.TableFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clean.Close()
+	report, err := clean.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Records != 3 {
+		t.Errorf("clean table: %d records, problems %v", report.Records, report.Problems)
+	}
+	r, err := clean.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note := r["NOTE"].([]byte); len(note) != 0 || r["N"] != int32(20) {
+		t.Errorf("record 1 of clean table = %v; want its memo cleared", r)
+	}
+	if r, err = clean.Get(0); err != nil {
+		t.Fatal(err)
+	}
+	if note := r["NOTE"].([]byte); string(note) != "abc" {
+		t.Errorf("memo of record 0 of clean table = %q, want %q", note, "abc")
+	}
+
+	noMemo, err := adt.FromReaders(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noMemo.CopyMemo(io.Discard); err != adt.ErrNoMemoFile {
+		t.Errorf("CopyMemo without a memo file: %v, want %v", err, adt.ErrNoMemoFile)
+	}
+}
+
+// TestSalvageNarrowColumns salvages a table whose column descriptors are
+// too short for their types.
+func TestSalvageNarrowColumns(t *testing.T) {
+	columns := []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeInt, Offset: 5, Length: 4},
+		{Name: "FLAG", Type: adt.ColumnTypeBool, Offset: 9, Length: 0},
+		{Name: "DAY", Type: adt.ColumnTypeDate, Offset: 9, Length: 2},
+		{Name: "AT", Type: adt.ColumnTypeTimestamp, Offset: 11, Length: 4},
+	}
+	table, err := adt.FromReaders(bytes.NewReader(adtBytes(columns, [][]byte{append(le32(7), "TTTTTT"...)})), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*adt.SalvagedRecord
+	if err := table.Salvage(func(r *adt.SalvagedRecord) error {
+		got = append(got, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Record["ID"] != int32(7) {
+		t.Fatalf("salvaged %v", got)
+	}
+	for _, name := range []string{"FLAG", "DAY", "AT"} {
+		if err := got[0].Errors[name]; err != adt.ErrColumnWidth {
+			t.Errorf("%s: error %v, want %v", name, err, adt.ErrColumnWidth)
+		}
+	}
+
+	report, err := table.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	narrow := 0
+	for _, p := range report.Problems {
+		if p.Kind == adt.ProblemColumnWidth {
+			narrow++
+		}
+	}
+	if narrow != 3 {
+		t.Errorf("problems %v, want 3 of kind %s", report.Problems, adt.ProblemColumnWidth)
+	}
+}
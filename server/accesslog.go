@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// requestInfo is what is learned while serving a request, for its access
+// log entry and metrics. It is carried in the request's context.
+type requestInfo struct {
+	id    string
+	route string
+
+	mu        sync.Mutex
+	table     string
+	principal string
+	// lookups caches the records looked up by lookupRecord, by table and
+	// primary key, for the rest of the request.
+	lookups map[lookupKey]map[string]interface{}
+	// referrers caches the rows read by the GraphQL referrer fields,
+	// grouped by the value of their foreign key, for the rest of the
+	// request.
+	referrers map[string]*referrerRows
+}
+
+type lookupKey struct {
+	table Table
+	pk    string
+}
+
+type requestInfoKey struct{}
+
+// info returns the requestInfo of the request with ctx, or nil.
+func info(ctx context.Context) *requestInfo {
+	i, _ := ctx.Value(requestInfoKey{}).(*requestInfo)
+	return i
+}
+
+// setTable notes the table file a request reads.
+func (i *requestInfo) setTable(db string) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	i.table = tableName(db)
+	i.mu.Unlock()
+}
+
+func (i *requestInfo) tableName() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.table
+}
+
+func (i *requestInfo) setPrincipal(principal string) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	i.principal = principal
+	i.mu.Unlock()
+}
+
+// lookup returns the cached record of table with primary key pk.
+func (i *requestInfo) lookup(table Table, pk string) (map[string]interface{}, bool) {
+	if i == nil {
+		return nil, false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	r, ok := i.lookups[lookupKey{table, pk}]
+	return r, ok
+}
+
+func (i *requestInfo) store(table Table, pk string, r map[string]interface{}) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.lookups == nil {
+		i.lookups = map[lookupKey]map[string]interface{}{}
+	}
+	i.lookups[lookupKey{table, pk}] = r
+}
+
+// lookupReferrers returns the cached rows read for the referrer field and
+// arguments described by key.
+func (i *requestInfo) lookupReferrers(key string) (*referrerRows, bool) {
+	if i == nil {
+		return nil, false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	r, ok := i.referrers[key]
+	return r, ok
+}
+
+func (i *requestInfo) storeReferrers(key string, r *referrerRows) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.referrers == nil {
+		i.referrers = map[string]*referrerRows{}
+	}
+	i.referrers[key] = r
+}
+
+// OpenAccessLog returns a logger writing JSON lines to the file at path,
+// which is appended to, or to standard error if path is "-". An empty path
+// returns nil, which logs nothing.
+func OpenAccessLog(path string) (*slog.Logger, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "-":
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil)), nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(slog.NewJSONHandler(f, nil)), nil
+}
+
+// RequestID returns the ID of the request with ctx, as sent back in its
+// X-Request-Id header and logged with it.
+func RequestID(ctx context.Context) string {
+	if i := info(ctx); i != nil {
+		return i.id
+	}
+	return ""
+}
+
+// requestID returns the request's X-Request-Id if it is a plausible ID,
+// otherwise a new random one.
+func requestID(r *http.Request) string {
+	if id := r.Header.Get("X-Request-Id"); len(id) > 0 && len(id) <= 64 {
+		ok := true
+		for _, c := range id {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return id
+		}
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder remembers the status and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// instrument gives each request an ID, and counts and logs it when it is
+// done.
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		i := &requestInfo{id: requestID(r), route: route(r.URL.Path)}
+		rw.Header().Set("X-Request-Id", i.id)
+		w := &statusRecorder{ResponseWriter: rw}
+		s.metrics.inFlight.Inc()
+		defer func() {
+			s.metrics.inFlight.Dec()
+			v := recover()
+			status := w.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			elapsed := time.Since(start)
+			s.metrics.observe(i, r.Method, status, elapsed.Seconds())
+			s.logRequest(r, i, status, w.bytes, elapsed, v != nil)
+			if v != nil {
+				panic(v)
+			}
+		}()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, i)))
+	})
+}
+
+// logRequest writes the access log entry of a request. aborted is set if
+// its handler gave up after sending the status.
+func (s *Server) logRequest(r *http.Request, i *requestInfo, status int, bytes int64, elapsed time.Duration, aborted bool) {
+	if s.AccessLog == nil {
+		return
+	}
+	i.mu.Lock()
+	table, principal := i.table, i.principal
+	i.mu.Unlock()
+	attrs := []slog.Attr{
+		slog.String("id", i.id),
+		slog.String("remote", clientAddr(r)),
+		slog.String("method", r.Method),
+		slog.String("uri", r.URL.RequestURI()),
+		slog.String("route", i.route),
+		slog.Int("status", status),
+		slog.Int64("bytes", bytes),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if table != "" {
+		attrs = append(attrs, slog.String("table", table))
+	}
+	if principal != "" {
+		attrs = append(attrs, slog.String("principal", principal))
+	}
+	if ua := r.UserAgent(); ua != "" {
+		attrs = append(attrs, slog.String("user_agent", ua))
+	}
+	if aborted {
+		attrs = append(attrs, slog.Bool("aborted", true))
+	}
+	s.AccessLog.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+}
@@ -0,0 +1,406 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+// Page sizes of the rows endpoint.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+var (
+	errNoTable   = errors.New("no such table")
+	errNoRecord  = errors.New("no such record")
+	errForbidden = errors.New("forbidden")
+)
+
+// TableInfo is an entry of the /api/tables listing.
+type TableInfo struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	Columns int    `json:"columns"`
+	Schema  string `json:"schema"`
+	Rows    string `json:"rows"`
+}
+
+// TableSchema is the response of /api/tables/{name}/schema.
+type TableSchema struct {
+	export.JSONSchema
+	Records    int      `json:"records"`
+	PrimaryKey []string `json:"primary_key,omitempty"`
+}
+
+// Rows is the response of /api/tables/{name}/rows. Total counts the rows
+// matching the query, of which Rows holds the page from Offset. Next is the
+// URL of the following page, if any.
+type Rows struct {
+	Table  string          `json:"table"`
+	Total  int             `json:"total"`
+	Offset int             `json:"offset"`
+	Limit  int             `json:"limit"`
+	Rows   json.RawMessage `json:"rows"`
+	Next   string          `json:"next,omitempty"`
+}
+
+// srvAPI routes the JSON API:
+//
+//	/api/tables
+//	/api/tables/{name}/schema
+//	/api/tables/{name}/rows?offset=&limit=&fields=&where=&order=
+//	/api/tables/{name}/export?format=&fields=&where=
+func (s *Server) srvAPI(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		apiError(rw, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "tables":
+		s.apiTables(rw, r)
+	case len(parts) == 3 && parts[0] == "tables" && parts[2] == "schema":
+		s.apiSchema(rw, r, parts[1])
+	case len(parts) == 3 && parts[0] == "tables" && parts[2] == "rows":
+		s.apiRows(rw, r, parts[1])
+	case len(parts) == 3 && parts[0] == "tables" && parts[2] == "export":
+		s.apiExport(rw, r, parts[1])
+	default:
+		apiError(rw, http.StatusNotFound, fmt.Errorf("no API endpoint %s", r.URL.Path))
+	}
+}
+
+func (s *Server) apiTables(rw http.ResponseWriter, r *http.Request) {
+	dbs, err := s.visible(r.Context())
+	if err != nil {
+		apiError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	tables := []TableInfo{}
+	for _, db := range dbs {
+		table, err := adt.TableFromPath(filepath.Join(s.path, db))
+		if err != nil {
+			apiError(rw, http.StatusInternalServerError, fmt.Errorf("%s: %v", db, err))
+			return
+		}
+		name := tableName(db)
+		tables = append(tables, TableInfo{
+			Name:    name,
+			Records: int(table.RecordCount),
+			Columns: len(table.Columns),
+			Schema:  "/api/tables/" + url.PathEscape(name) + "/schema",
+			Rows:    "/api/tables/" + url.PathEscape(name) + "/rows",
+		})
+		table.Close()
+	}
+	writeJSON(rw, http.StatusOK, struct {
+		Tables []TableInfo `json:"tables"`
+	}{tables})
+}
+
+func (s *Server) apiSchema(rw http.ResponseWriter, r *http.Request, name string) {
+	table, _, ok := s.apiTable(rw, r, name)
+	if !ok {
+		return
+	}
+	defer table.Close()
+	schema := TableSchema{
+		JSONSchema: export.NewJSONSchema(table, table.Columns),
+		Records:    int(table.RecordCount),
+	}
+	schema.Table = tableName(table.Name)
+	if pk, err := table.GetPK(); err == nil && pk != nil {
+		schema.PrimaryKey = []string{pk.Name}
+	}
+	writeJSON(rw, http.StatusOK, schema)
+}
+
+// rowsQuery holds the parsed parameters of a rows request.
+type rowsQuery struct {
+	offset, limit int
+	fields        []string
+	where         []*filter
+	order         []sortKey
+	access        *access
+}
+
+type sortKey struct {
+	column *adt.Column
+	desc   bool
+}
+
+// parseRowsQuery parses offset, limit, fields (comma separated), where
+// (repeated, all must match) and order (comma separated, "-" prefix for
+// descending) against the columns of t that a may read.
+func parseRowsQuery(t *adt.Table, a *access, q url.Values) (*rowsQuery, error) {
+	rq := &rowsQuery{limit: DefaultPageSize, access: a}
+	var err error
+	if v := q.Get("offset"); v != "" {
+		if rq.offset, err = strconv.Atoi(v); err != nil || rq.offset < 0 {
+			return nil, fmt.Errorf("bad offset %q", v)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if rq.limit, err = strconv.Atoi(v); err != nil || rq.limit < 0 || rq.limit > MaxPageSize {
+			return nil, fmt.Errorf("bad limit %q: want 0 to %d", v, MaxPageSize)
+		}
+	}
+	if v := q.Get("fields"); v != "" {
+		rq.fields = strings.Split(v, ",")
+		if _, err := export.SelectColumns(t, rq.fields); err != nil {
+			return nil, err
+		}
+	}
+	if rq.where, err = parseWhere(t, a, q); err != nil {
+		return nil, err
+	}
+	if v := q.Get("order"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			key := sortKey{desc: strings.HasPrefix(name, "-")}
+			name = strings.TrimPrefix(name, "-")
+			if key.column = findColumn(t, name); key.column == nil {
+				return nil, fmt.Errorf("no column %q to order by", name)
+			}
+			if !a.allowed(key.column) {
+				return nil, fmt.Errorf("column %s is masked", key.column.Name)
+			}
+			rq.order = append(rq.order, key)
+		}
+	}
+	return rq, nil
+}
+
+// parseWhere parses the query's where conditions against the columns of t
+// that a may read.
+func parseWhere(t *adt.Table, a *access, q url.Values) ([]*filter, error) {
+	var where []*filter
+	for _, expr := range q["where"] {
+		f, err := parseFilter(t, expr)
+		if err != nil {
+			return nil, err
+		}
+		if !a.allowed(f.column) {
+			return nil, fmt.Errorf("column %s is masked", f.column.Name)
+		}
+		where = append(where, f)
+	}
+	return where, nil
+}
+
+func (rq *rowsQuery) match(r adt.Record) bool {
+	return matchAll(rq.where, r)
+}
+
+// matchAll reports whether r satisfies every condition of where.
+func matchAll(where []*filter, r adt.Record) bool {
+	for _, f := range where {
+		if !f.match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// less orders a before b by the query's sort keys, NULLs first.
+func (rq *rowsQuery) less(a, b adt.Record) bool {
+	for _, k := range rq.order {
+		va, vb := a[k.column.Name], b[k.column.Name]
+		var c int
+		switch {
+		case va == nil && vb == nil:
+			continue
+		case va == nil:
+			c = -1
+		case vb == nil:
+			c = 1
+		default:
+			c = compareValues(k.column, va, vb)
+		}
+		if c == 0 {
+			continue
+		}
+		return (c < 0) != k.desc
+	}
+	return false
+}
+
+// apiRows serves a page of the table's active records.
+func (s *Server) apiRows(rw http.ResponseWriter, r *http.Request, name string) {
+	table, a, ok := s.apiTable(rw, r, name)
+	if !ok {
+		return
+	}
+	defer table.Close()
+	rq, err := parseRowsQuery(table, a, r.URL.Query())
+	if err != nil {
+		apiError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	page, total, err := s.queryRows(table, rq)
+	if err != nil {
+		apiError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	w, err := export.NewJSONWriter(&buf, table, export.JSONOptions{Array: true, Columns: rq.fields})
+	if err != nil {
+		apiError(rw, http.StatusBadRequest, err)
+		return
+	}
+	for _, rec := range page {
+		if err := w.Write(rec); err != nil {
+			apiError(rw, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if err := w.Close(); err != nil {
+		apiError(rw, http.StatusInternalServerError, err)
+		return
+	}
+
+	result := Rows{
+		Table:  tableName(table.Name),
+		Total:  total,
+		Offset: rq.offset,
+		Limit:  rq.limit,
+		Rows:   json.RawMessage(bytes.TrimSpace(buf.Bytes())),
+	}
+	if next := rq.offset + len(page); rq.limit > 0 && next < total {
+		q := r.URL.Query()
+		q.Set("offset", strconv.Itoa(next))
+		q.Set("limit", strconv.Itoa(rq.limit))
+		result.Next = r.URL.Path + "?" + q.Encode()
+	}
+	writeJSON(rw, http.StatusOK, result)
+}
+
+// queryRows returns the page of t's active records selected by rq and the
+// number of records matching its conditions. Without an order the table is
+// scanned once, keeping only the page; with one, the matching records are
+// sorted in memory.
+func (s *Server) queryRows(t *adt.Table, rq *rowsQuery) ([]adt.Record, int, error) {
+	page, _, total, err := s.queryIndexed(t, rq)
+	return page, total, err
+}
+
+// queryIndexed is like queryRows but also returns the index in t of each
+// record of the page.
+func (s *Server) queryIndexed(t *adt.Table, rq *rowsQuery) ([]adt.Record, []int, int, error) {
+	type row struct {
+		index int
+		rec   adt.Record
+	}
+	var page, matched []row
+	total, scanned := 0, 0
+	records := export.Range{Count: -1, Deleted: export.DeletedSkip}
+	err := records.Each(t, func(i int, rec adt.Record) error {
+		scanned++
+		rq.access.mask(t, rec)
+		if !rq.match(rec) {
+			return nil
+		}
+		if rq.order != nil {
+			matched = append(matched, row{i, rec})
+		} else if total >= rq.offset && total < rq.offset+rq.limit {
+			page = append(page, row{i, rec})
+		}
+		total++
+		return nil
+	})
+	s.metrics.scanned(t.Name, scanned)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if rq.order != nil {
+		sort.SliceStable(matched, func(i, j int) bool { return rq.less(matched[i].rec, matched[j].rec) })
+		if rq.offset < len(matched) {
+			end := rq.offset + rq.limit
+			if end > len(matched) {
+				end = len(matched)
+			}
+			page = matched[rq.offset:end]
+		}
+	}
+	var recs []adt.Record
+	var indexes []int
+	for _, r := range page {
+		recs = append(recs, r.rec)
+		indexes = append(indexes, r.index)
+	}
+	return recs, indexes, total, nil
+}
+
+// apiTable opens the named table and returns what the request's principal
+// may read of it. It writes a 404 if there is no such table or the
+// principal may not read it.
+func (s *Server) apiTable(rw http.ResponseWriter, r *http.Request, name string) (*adt.Table, *access, bool) {
+	db, table, err := s.openTable(name)
+	if err == errNoTable {
+		apiError(rw, http.StatusNotFound, fmt.Errorf("no table %q", name))
+		return nil, nil, false
+	}
+	if err != nil {
+		apiError(rw, http.StatusInternalServerError, err)
+		return nil, nil, false
+	}
+	a, ok := s.access(r.Context(), db, table)
+	if !ok {
+		table.Close()
+		apiError(rw, http.StatusNotFound, fmt.Errorf("no table %q", name))
+		return nil, nil, false
+	}
+	info(r.Context()).setTable(db)
+	return table, a, true
+}
+
+// openTable opens the table called name, with or without its .ADT
+// extension, in any case, and returns the name of its file, which is what
+// policies and the Config are matched against. Only files listed by
+// listdbs are opened, so names can't reach outside the server's directory.
+func (s *Server) openTable(name string) (string, *adt.Table, error) {
+	dbs, err := s.listdbs()
+	if err != nil {
+		return "", nil, err
+	}
+	for _, db := range dbs {
+		if strings.EqualFold(db, name) || strings.EqualFold(tableName(db), name) {
+			table, err := adt.TableFromPath(filepath.Join(s.path, db))
+			return db, table, err
+		}
+	}
+	return "", nil, errNoTable
+}
+
+// tableName is the name of a table file without its extension.
+func tableName(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file))
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	enc := json.NewEncoder(rw)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func apiError(rw http.ResponseWriter, status int, err error) {
+	writeJSON(rw, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
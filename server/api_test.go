@@ -0,0 +1,303 @@
+package server_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/server"
+)
+
+// writeADT writes a table file to dir holding records, each prefixed with
+// the active record flags.
+func writeADT(t *testing.T, dir, file string, columns []*adt.Column, records [][]byte) {
+	t.Helper()
+	recordLength := 5
+	for _, c := range columns {
+		recordLength += int(c.Length)
+	}
+	dataOffset := adt.HeaderLength + adt.ColumnDescriptorLength*len(columns)
+	buf := make([]byte, dataOffset)
+	copy(buf, adt.MagicHeader)
+	binary.LittleEndian.PutUint32(buf[24:], uint32(len(records)))
+	binary.LittleEndian.PutUint16(buf[32:], uint16(dataOffset))
+	binary.LittleEndian.PutUint32(buf[36:], uint32(recordLength))
+	for i, c := range columns {
+		d := buf[adt.HeaderLength+adt.ColumnDescriptorLength*i:]
+		copy(d, c.Name)
+		d[129] = byte(c.Type)
+		binary.LittleEndian.PutUint16(d[131:], c.Offset)
+		binary.BigEndian.PutUint16(d[134:], c.Length)
+	}
+	for _, r := range records {
+		rec := make([]byte, recordLength)
+		copy(rec, adt.RecordMagicHeader)
+		copy(rec[5:], r)
+		buf = append(buf, rec...)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func le32(v int32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+// writeTable writes PEOPLE.ADT to dir: an AutoIncrement ID, a 6 byte NAME
+// and an Int AGE per record, with record 2 deleted.
+func writeTable(t *testing.T, dir string, names []string, ages []int32) {
+	t.Helper()
+	columns := []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Offset: 5, Length: 4},
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 9, Length: 6},
+		{Name: "AGE", Type: adt.ColumnTypeInt, Offset: 15, Length: 4},
+	}
+	var records [][]byte
+	for i, name := range names {
+		rec := append(le32(int32(i+1)), fmt.Sprintf("%-6s", name)...)
+		records = append(records, append(rec, le32(ages[i])...))
+	}
+	writeADT(t, dir, "PEOPLE.ADT", columns, records)
+	if len(names) > 2 {
+		f, err := os.OpenFile(filepath.Join(dir, "PEOPLE.ADT"), os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		offset := int64(adt.HeaderLength + adt.ColumnDescriptorLength*len(columns) + 19*2)
+		if _, err := f.WriteAt([]byte{adt.RecordFlagDeleted}, offset); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func get(t *testing.T, h http.Handler, url string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: %v\n%s", url, err, rec.Body)
+	}
+	return rec.Code
+}
+
+func TestAPI(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob", "cy", "dee", "ed"}, []int32{30, 25, 40, 35, -2147483648})
+	h := server.New(nil, dir, false).Handler()
+
+	var tables struct{ Tables []server.TableInfo }
+	if code := get(t, h, "/api/tables", &tables); code != 200 || len(tables.Tables) != 1 || tables.Tables[0].Name != "PEOPLE" {
+		t.Fatalf("tables: %d %+v", code, tables)
+	}
+
+	var schema server.TableSchema
+	if code := get(t, h, "/api/tables/people/schema", &schema); code != 200 || len(schema.Columns) != 3 || !reflect.DeepEqual(schema.PrimaryKey, []string{"ID"}) {
+		t.Fatalf("schema: %d %+v", code, schema)
+	}
+
+	type row struct {
+		ID   int
+		NAME string
+		AGE  *int
+	}
+	tests := []struct {
+		query string
+		total int
+		ids   []int
+		next  string
+	}{
+		{"", 4, []int{1, 2, 4, 5}, ""},
+		{"?limit=2", 4, []int{1, 2}, "/api/tables/PEOPLE/rows?limit=2&offset=2"},
+		{"?limit=2&offset=2", 4, []int{4, 5}, ""},
+		{"?where=AGE>=30", 2, []int{1, 4}, ""},
+		{"?where=AGE=null", 1, []int{5}, ""},
+		{"?where=NAME~E&where=ID!=5", 1, []int{4}, ""},
+		{"?order=-AGE&limit=3", 4, []int{4, 1, 2}, "/api/tables/PEOPLE/rows?limit=3&offset=3&order=-AGE"},
+		{"?order=AGE&fields=id", 4, []int{5, 2, 1, 4}, ""},
+	}
+	for _, tt := range tests {
+		var rows struct {
+			Total int
+			Next  string
+			Rows  []row
+		}
+		if code := get(t, h, "/api/tables/PEOPLE/rows"+tt.query, &rows); code != 200 {
+			t.Errorf("%s: status %d", tt.query, code)
+			continue
+		}
+		var ids []int
+		for _, r := range rows.Rows {
+			ids = append(ids, r.ID)
+		}
+		if rows.Total != tt.total || !reflect.DeepEqual(ids, tt.ids) || rows.Next != tt.next {
+			t.Errorf("%s: total %d, ids %v, next %q; want %d, %v, %q", tt.query, rows.Total, ids, rows.Next, tt.total, tt.ids, tt.next)
+		}
+	}
+
+	for _, url := range []string{
+		"/api/tables/PEOPLE/rows?where=AGE>old",
+		"/api/tables/PEOPLE/rows?order=HEIGHT",
+		"/api/tables/PEOPLE/rows?limit=5000",
+		"/api/tables/PEOPLE/rows?fields=HEIGHT",
+	} {
+		var e struct{ Error string }
+		if code := get(t, h, url, &e); code != 400 || e.Error == "" {
+			t.Errorf("%s: status %d, error %q; want 400", url, code, e.Error)
+		}
+	}
+	var e struct{ Error string }
+	if code := get(t, h, "/api/tables/NOPE/rows", &e); code != 404 {
+		t.Errorf("missing table: status %d", code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann"}, []int32{30})
+	h := server.New(server.Config{"PEOPLE.ADT": {"AGE": "AGES.ADT"}}, dir, false).Handler()
+
+	var doc struct {
+		OpenAPI    string
+		Paths      map[string]json.RawMessage
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string
+				Properties map[string]map[string]interface{}
+			}
+		}
+	}
+	if code := get(t, h, "/openapi.json", &doc); code != 200 || doc.OpenAPI != "3.0.3" {
+		t.Fatalf("status %d, openapi %q", code, doc.OpenAPI)
+	}
+	if _, ok := doc.Paths["/api/tables/PEOPLE/rows"]; !ok {
+		t.Errorf("no rows path for PEOPLE in %v", doc.Paths)
+	}
+	row, ok := doc.Components.Schemas["PeopleRow"]
+	if !ok {
+		t.Fatal("no PeopleRow schema")
+	}
+	if !reflect.DeepEqual(row.Required, []string{"ID", "NAME"}) {
+		t.Errorf("required = %v", row.Required)
+	}
+	want := map[string]interface{}{
+		"ID":   "integer",
+		"NAME": "string",
+		"AGE":  "integer",
+	}
+	for name, typ := range want {
+		if got := row.Properties[name]["type"]; got != typ {
+			t.Errorf("%s type = %v, want %v", name, got, typ)
+		}
+	}
+	if got := row.Properties["AGE"]["x-references"]; got != "AGES" {
+		t.Errorf("AGE x-references = %v, want AGES", got)
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob", "cy", "dee"}, []int32{30, 25, 40, 35})
+	h := server.New(nil, dir, false).Handler()
+
+	tests := []struct {
+		query, contentType, file, body string
+		prefix                         bool // body is only the start of the response
+	}{
+		{"?where=AGE>26&fields=NAME,AGE", "application/x-ndjson", "PEOPLE.ndjson", "{\"NAME\":\"ann\",\"AGE\":30}\n{\"NAME\":\"dee\",\"AGE\":35}\n", false},
+		{"?format=csv&fields=ID,NAME", "text/csv; charset=utf-8", "PEOPLE.csv", "ID,NAME\n1,ann\n2,bob\n4,dee\n", false},
+		{"?format=parquet", "application/vnd.apache.parquet", "PEOPLE.parquet", "PAR1", true},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/api/tables/PEOPLE/export"+tt.query, nil))
+		// The headers as sent with the status, not as set afterwards.
+		header := rec.Result().Header
+		body := rec.Body.String()
+		if tt.prefix && strings.HasPrefix(body, tt.body) {
+			body = tt.body
+		}
+		if rec.Code != 200 || header.Get("Content-Type") != tt.contentType || body != tt.body {
+			t.Errorf("%s: %d %s\n%s", tt.query, rec.Code, header.Get("Content-Type"), rec.Body)
+		}
+		if cd := header.Get("Content-Disposition"); cd != `attachment; filename="`+tt.file+`"` {
+			t.Errorf("%s: Content-Disposition %q", tt.query, cd)
+		}
+	}
+
+	var e struct{ Error string }
+	if code := get(t, h, "/api/tables/PEOPLE/export?format=xml", &e); code != 400 {
+		t.Errorf("format=xml: status %d", code)
+	}
+
+	// A request whose client has gone away is abandoned.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("canceled export: recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/tables/PEOPLE/export", nil).WithContext(ctx))
+}
+
+func TestRelatedRecords(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann"}, []int32{30})
+	// PETS has no primary key for TOYS to refer to.
+	writeADT(t, dir, "PETS.ADT", []*adt.Column{
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 5, Length: 4},
+		{Name: "OWNER", Type: adt.ColumnTypeInt, Offset: 9, Length: 4},
+	}, [][]byte{
+		append([]byte("rex "), le32(1)...),
+		append([]byte("tom "), le32(9)...),
+	})
+	writeADT(t, dir, "TOYS.ADT", []*adt.Column{
+		{Name: "PET", Type: adt.ColumnTypeInt, Offset: 5, Length: 4},
+	}, [][]byte{le32(1)})
+	h := server.New(server.Config{
+		"PETS.ADT": {"OWNER": "PEOPLE.ADT"},
+		"TOYS.ADT": {"PET": "PETS.ADT"},
+	}, dir, false).Handler()
+
+	tests := []struct {
+		url, column string
+		want        interface{}
+	}{
+		{"/dbs/PETS.ADT/1", "OWNER", map[string]interface{}{"ID": 1.0, "NAME": "ann", "AGE": 30.0}},
+		// tom's owner is missing and PETS has no primary key: the values
+		// are kept.
+		{"/dbs/PETS.ADT/2", "OWNER", 9.0},
+		{"/dbs/TOYS.ADT/1", "PET", 1.0},
+	}
+	for _, tt := range tests {
+		var rec map[string]interface{}
+		if code := get(t, h, tt.url, &rec); code != 200 {
+			t.Fatalf("%s: status %d", tt.url, code)
+		}
+		if got := rec[tt.column]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %s = %v, want %v", tt.url, tt.column, got, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ PETS { rows { OWNER_PEOPLE { NAME } } } }"}`)))
+	var got, want interface{}
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	json.Unmarshal([]byte(`{"data": {"PETS": {"rows": [{"OWNER_PEOPLE": {"NAME": "ann"}}, {"OWNER_PEOPLE": null}]}}}`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GraphQL reference to a missing row:\n%s", rec.Body)
+	}
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tmc/adt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// An Authenticator identifies the principal making a request.
+type Authenticator interface {
+	// Authenticate returns the principal named by r's credentials, "" if r
+	// carries none it recognizes, or an error if they are wrong.
+	Authenticate(r *http.Request) (string, error)
+	// Challenge is the WWW-Authenticate header sent with a 401, or "".
+	Challenge() string
+}
+
+var errBadCredentials = errors.New("bad credentials")
+
+// BearerTokens authenticates "Authorization: Bearer" headers. It maps
+// tokens to principals.
+type BearerTokens map[string]string
+
+func (b BearerTokens) Authenticate(r *http.Request) (string, error) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "bearer ") {
+		return "", nil
+	}
+	token := strings.TrimSpace(h[7:])
+	for t, principal := range b {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return principal, nil
+		}
+	}
+	return "", errBadCredentials
+}
+
+func (b BearerTokens) Challenge() string { return `Bearer realm="adt"` }
+
+// BasicAuth authenticates HTTP basic credentials. It maps user names, which
+// are the principals, to bcrypt password hashes.
+type BasicAuth map[string]string
+
+func (b BasicAuth) Authenticate(r *http.Request) (string, error) {
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return "", nil
+	}
+	hash, ok := b[user]
+	if !ok {
+		// Spend the same time as for a known user.
+		hash = "$2a$10$dnKEKxV4hum1b6NgA/EEXedksq8fV4hrus6BGzW4WMNY.gf5qAeZy"
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil || !ok {
+		return "", errBadCredentials
+	}
+	return user, nil
+}
+
+func (b BasicAuth) Challenge() string { return `Basic realm="adt", charset="UTF-8"` }
+
+// ClientCert authenticates TLS client certificates verified against the
+// server's ClientCAs. The principal is the certificate's common name.
+type ClientCert struct{}
+
+func (ClientCert) Authenticate(r *http.Request) (string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", nil
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName, nil
+}
+
+func (ClientCert) Challenge() string { return "" }
+
+// Policy maps principals to the tables they may read. The principal "*"
+// applies to any principal without an entry of its own; with no
+// Authenticators every request is made by the principal "".
+type Policy map[string][]Grant
+
+// Grant allows reading a table.
+type Grant struct {
+	// Table is the table's name, with or without its extension, or "*"
+	// for every table.
+	Table string `json:"table"`
+	// Columns lists the columns whose values may be read; empty allows
+	// them all. Other columns are masked: they keep their place in
+	// schemas and rows but always read as NULL, and can't be filtered or
+	// sorted on.
+	Columns []string `json:"columns,omitempty"`
+}
+
+// access is what a principal may read of one table.
+type access struct {
+	// masked holds the upper-cased names of masked columns.
+	masked map[string]bool
+}
+
+// allowed reports whether c may be read.
+func (a *access) allowed(c *adt.Column) bool {
+	return a == nil || !a.masked[strings.ToUpper(c.Name)]
+}
+
+// mask clears the masked columns of r.
+func (a *access) mask(t *adt.Table, r adt.Record) {
+	if a == nil || len(a.masked) == 0 {
+		return
+	}
+	for _, c := range t.Columns {
+		if a.masked[strings.ToUpper(c.Name)] {
+			r[c.Name] = nil
+		}
+	}
+}
+
+// maskedColumns returns the names of the masked columns of t.
+func (a *access) maskedColumns(t *adt.Table) []string {
+	var result []string
+	for _, c := range t.Columns {
+		if !a.allowed(c) {
+			result = append(result, c.Name)
+		}
+	}
+	return result
+}
+
+// grants returns the grants of principal.
+func (p Policy) grants(principal string) []Grant {
+	if grants, ok := p[principal]; ok {
+		return grants
+	}
+	return p["*"]
+}
+
+// matches reports whether g applies to the table file db.
+func (g Grant) matches(db string) bool {
+	return g.Table == "*" || strings.EqualFold(g.Table, db) || strings.EqualFold(g.Table, tableName(db))
+}
+
+// grant returns what principal may read of t, read from the file db. ok is
+// false if the policy doesn't let it read t at all.
+func (p Policy) grant(principal, db string, t *adt.Table) (*access, bool) {
+	for _, g := range p.grants(principal) {
+		if !g.matches(db) {
+			continue
+		}
+		a := &access{masked: map[string]bool{}}
+		if len(g.Columns) > 0 {
+			allowed := map[string]bool{}
+			for _, c := range g.Columns {
+				allowed[strings.ToUpper(c)] = true
+			}
+			for _, c := range t.Columns {
+				if !allowed[strings.ToUpper(c.Name)] {
+					a.masked[strings.ToUpper(c.Name)] = true
+				}
+			}
+		}
+		return a, true
+	}
+	return nil, false
+}
+
+type principalKey struct{}
+
+// Principal returns the principal authenticated for the request with ctx.
+func Principal(ctx context.Context) string {
+	p, _ := ctx.Value(principalKey{}).(string)
+	return p
+}
+
+// authenticate runs the server's Authenticators over r, returning r with
+// the principal in its context, or writes a 401 and returns nil.
+func (s *Server) authenticate(rw http.ResponseWriter, r *http.Request) *http.Request {
+	if len(s.Auth) == 0 {
+		return r
+	}
+	for _, a := range s.Auth {
+		principal, err := a.Authenticate(r)
+		if err == nil && principal == "" {
+			continue
+		}
+		if err == nil {
+			info(r.Context()).setPrincipal(principal)
+			return r.WithContext(context.WithValue(r.Context(), principalKey{}, principal))
+		}
+		break
+	}
+	for _, a := range s.Auth {
+		if c := a.Challenge(); c != "" {
+			rw.Header().Add("WWW-Authenticate", c)
+		}
+	}
+	apiError(rw, http.StatusUnauthorized, errors.New("authentication required"))
+	return nil
+}
+
+// access returns what the request's principal may read of t, read from
+// the file db. ok is false if it may not read t.
+func (s *Server) access(ctx context.Context, db string, t *adt.Table) (*access, bool) {
+	if s.Policy == nil {
+		return nil, true
+	}
+	return s.Policy.grant(Principal(ctx), db, t)
+}
+
+// visible returns the table files the request's principal may read.
+func (s *Server) visible(ctx context.Context) ([]string, error) {
+	dbs, err := s.listdbs()
+	if err != nil || s.Policy == nil {
+		return dbs, err
+	}
+	grants := s.Policy.grants(Principal(ctx))
+	var result []string
+	for _, db := range dbs {
+		for _, g := range grants {
+			if g.matches(db) {
+				result = append(result, db)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// AuthFiles names the files configuring a Server's authentication and
+// authorization. Empty names are skipped.
+type AuthFiles struct {
+	Policy   string // JSON Policy, see LoadPolicy
+	Tokens   string // bearer tokens, see LoadBearerTokens
+	Htpasswd string // basic auth users, see LoadBasicAuth
+	ClientCA string // PEM CA certificates for client certificates
+}
+
+// LoadAuth sets up s's Auth, Policy and ClientCAs from files.
+func (s *Server) LoadAuth(files AuthFiles) error {
+	var err error
+	if s.Policy, err = LoadPolicy(files.Policy); err != nil {
+		return err
+	}
+	if files.Tokens != "" {
+		tokens, err := LoadBearerTokens(files.Tokens)
+		if err != nil {
+			return err
+		}
+		s.Auth = append(s.Auth, tokens)
+	}
+	if files.Htpasswd != "" {
+		users, err := LoadBasicAuth(files.Htpasswd)
+		if err != nil {
+			return err
+		}
+		s.Auth = append(s.Auth, users)
+	}
+	if files.ClientCA != "" {
+		if s.ClientCAs, err = LoadCertPool(files.ClientCA); err != nil {
+			return err
+		}
+		s.Auth = append(s.Auth, ClientCert{})
+	}
+	return nil
+}
+
+// LoadPolicy reads a Policy from the JSON file at path. An empty path
+// returns a nil Policy, which allows everything.
+func LoadPolicy(path string) (Policy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := Policy{}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// LoadBearerTokens reads "principal token" lines from the file at path.
+// Blank lines and lines starting with # are ignored.
+func LoadBearerTokens(path string) (BearerTokens, error) {
+	b := BearerTokens{}
+	return b, readLines(path, func(line string) error {
+		f := strings.Fields(line)
+		if len(f) != 2 {
+			return errors.New("want principal and token")
+		}
+		b[f[1]] = f[0]
+		return nil
+	})
+}
+
+// LoadBasicAuth reads "user:bcrypt-hash" lines, as written by
+// htpasswd -B, from the file at path.
+func LoadBasicAuth(path string) (BasicAuth, error) {
+	b := BasicAuth{}
+	return b, readLines(path, func(line string) error {
+		i := strings.IndexByte(line, ':')
+		if i <= 0 {
+			return errors.New("want user:hash")
+		}
+		if _, err := bcrypt.Cost([]byte(line[i+1:])); err != nil {
+			return fmt.Errorf("user %s: %v", line[:i], err)
+		}
+		b[line[:i]] = line[i+1:]
+		return nil
+	})
+}
+
+// LoadCertPool reads PEM encoded CA certificates from the file at path.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("%s: no certificates", path)
+	}
+	return pool, nil
+}
+
+func readLines(path string, fn func(line string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+	}
+	return sc.Err()
+}
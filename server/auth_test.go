@@ -0,0 +1,167 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/adt/server"
+)
+
+func TestAuth(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob"}, []int32{30, 25})
+	srv := server.New(nil, dir, false)
+	srv.Auth = []server.Authenticator{
+		server.BearerTokens{"t0ken": "alice"},
+		// s3cret
+		server.BasicAuth{"bob": "$2a$04$BwGzBqE6.B.jtUzYfdvwRecYlriayhj42woSu2YIv9dGbmuH.GeTO"},
+		server.ClientCert{},
+	}
+	srv.Policy = server.Policy{
+		"alice": {{Table: "PEOPLE", Columns: []string{"ID", "NAME"}}},
+		"carol": {{Table: "*"}},
+	}
+	h := srv.Handler()
+
+	type request struct {
+		url    string
+		header string
+		user   string
+		cert   string
+	}
+	do := func(req request) (int, map[string]interface{}) {
+		t.Helper()
+		r := httptest.NewRequest("GET", req.url, nil)
+		if req.header != "" {
+			r.Header.Set("Authorization", req.header)
+		}
+		if req.user != "" {
+			r.SetBasicAuth(req.user, "s3cret")
+		}
+		if req.cert != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: req.cert}}
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v\n%s", req.url, err, rec.Body)
+		}
+		if rec.Code == 401 && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: 401 without a challenge", req.url)
+		}
+		return rec.Code, body
+	}
+
+	for _, tt := range []struct {
+		req  request
+		code int
+	}{
+		{request{url: "/api/tables"}, 401},
+		{request{url: "/api/tables", header: "Bearer wrong"}, 401},
+		{request{url: "/api/tables", header: "Bearer t0ken"}, 200},
+		{request{url: "/api/tables", user: "bob"}, 200},
+		{request{url: "/api/tables", cert: "carol"}, 200},
+		// bob has no grants.
+		{request{url: "/api/tables/PEOPLE/rows", user: "bob"}, 404},
+		{request{url: "/api/tables/PEOPLE/rows", cert: "carol"}, 200},
+		{request{url: "/api/tables/PEOPLE/rows", header: "Bearer t0ken"}, 200},
+		// AGE is masked for alice.
+		{request{url: "/api/tables/PEOPLE/rows?where=AGE>1", header: "Bearer t0ken"}, 400},
+		{request{url: "/api/tables/PEOPLE/rows?order=AGE", header: "Bearer t0ken"}, 400},
+	} {
+		if code, body := do(tt.req); code != tt.code {
+			t.Errorf("%+v: status %d, want %d: %v", tt.req, code, tt.code, body)
+		}
+	}
+
+	if _, body := do(request{url: "/api/tables", user: "bob"}); len(body["tables"].([]interface{})) != 0 {
+		t.Errorf("bob sees tables %v", body["tables"])
+	}
+	_, body := do(request{url: "/api/tables/PEOPLE/rows", header: "Bearer t0ken"})
+	row := body["rows"].([]interface{})[0].(map[string]interface{})
+	if row["NAME"] != "ann" || row["AGE"] != nil {
+		t.Errorf("alice's row = %v, want NAME with AGE masked", row)
+	}
+	_, body = do(request{url: "/api/tables/PEOPLE/rows", cert: "carol"})
+	if row := body["rows"].([]interface{})[0].(map[string]interface{}); row["AGE"] != 30.0 {
+		t.Errorf("carol's row = %v, want AGE", row)
+	}
+}
+
+// TestGrantNames checks that grants apply to a table whichever way the
+// grant and the request name it.
+func TestGrantNames(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob"}, []int32{30, 25})
+	for _, grant := range []string{"PEOPLE.ADT", "people"} {
+		srv := server.New(nil, dir, false)
+		srv.Policy = server.Policy{"*": {{Table: grant, Columns: []string{"ID"}}, {Table: "*"}}}
+		h := srv.Handler()
+		for _, url := range []string{
+			"/api/tables/PEOPLE/rows",
+			"/api/tables/PEOPLE.ADT/rows",
+			"/api/tables/people.adt/rows",
+		} {
+			var body struct{ Rows []map[string]interface{} }
+			if code := get(t, h, url, &body); code != 200 || len(body.Rows) == 0 {
+				t.Fatalf("grant %s, %s: status %d, rows %v", grant, url, code, body.Rows)
+			}
+			if row := body.Rows[0]; row["NAME"] != nil || row["AGE"] != nil {
+				t.Errorf("grant %s, %s: row %v, want NAME and AGE masked", grant, url, row)
+			}
+		}
+		for _, url := range []string{"/dbs/PEOPLE/1", "/dbs/PEOPLE.ADT/1"} {
+			var row map[string]interface{}
+			if code := get(t, h, url, &row); code != 200 || row["NAME"] != nil || row["AGE"] != nil {
+				t.Errorf("grant %s, %s: status %d, record %v, want NAME and AGE masked", grant, url, code, row)
+			}
+		}
+	}
+}
+
+func TestMaskedParquetExport(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob"}, []int32{30, 25})
+	srv := server.New(nil, dir, false)
+	srv.Auth = []server.Authenticator{server.BearerTokens{"t0ken": "alice"}}
+	// NAME can't hold NULL, so masking it needs an OPTIONAL parquet column.
+	srv.Policy = server.Policy{"alice": {{Table: "PEOPLE", Columns: []string{"ID", "AGE"}}}}
+	h := srv.Handler()
+
+	r := httptest.NewRequest("GET", "/api/tables/PEOPLE/export?format=parquet", nil)
+	r.Header.Set("Authorization", "Bearer t0ken")
+	rec := httptest.NewRecorder()
+	defer func() {
+		if v := recover(); v != nil {
+			t.Fatalf("masked parquet export aborted: %v", v)
+		}
+	}()
+	h.ServeHTTP(rec, r)
+	if rec.Code != 200 || !strings.HasSuffix(rec.Body.String(), "PAR1") {
+		t.Errorf("status %d, body %q", rec.Code, rec.Body)
+	}
+}
+
+func TestServeClientCAsWithoutTLS(t *testing.T) {
+	srv := server.New(nil, t.TempDir(), false)
+	srv.ClientCAs = x509.NewCertPool()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ServeContext(context.Background(), "127.0.0.1:0", "", "") }()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("ServeContext with ClientCAs and no TLS: no error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeContext with ClientCAs and no TLS is serving")
+	}
+}
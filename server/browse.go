@@ -0,0 +1,318 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GeertJohan/go.rice"
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+// browsePageSize is the number of rows on a page of the table browser.
+const browsePageSize = 50
+
+// memoPreview is the number of characters of a memo shown in a table.
+const memoPreview = 80
+
+// tablePage is the data of db.tmpl: a page of a table's rows.
+type tablePage struct {
+	Name     string // table file
+	Columns  []pageColumn
+	Rows     []pageRow
+	Total    int // rows matching the filters
+	From, To int // positions of the page's rows among them, counting from 1
+	Filtered bool
+	Order    string
+	Prev     string
+	Next     string
+	Error    string
+}
+
+type pageColumn struct {
+	Name   string
+	Type   string
+	Masked bool
+	Sort   string // URL of the page sorted by the column
+	Sorted string // "asc" or "desc" if the page is sorted by the column
+	Filter string // the column's filter, see filterExpr
+}
+
+type pageRow struct {
+	Record int // record number, counting from 1
+	Link   string
+	Cells  []cell
+}
+
+// cell is a value shown by the browser.
+type cell struct {
+	Text    string
+	Null    bool
+	Masked  bool
+	Memo    bool
+	Clipped bool   // Text is the start of a longer memo
+	Link    string // the related record, for foreign keys
+}
+
+// recordPage is the data of record.tmpl: one record.
+type recordPage struct {
+	Name      string // table file
+	Table     string // URL of the table's first page
+	Record    int
+	Records   int
+	Deleted   bool
+	Fields    []recordField
+	Referrers []referrer
+	Prev      string
+	Next      string
+}
+
+type recordField struct {
+	Name string
+	Type string
+	Cell cell
+}
+
+// referrer links to the rows of another table referring to a record.
+type referrer struct {
+	Table  string
+	Column string
+	Link   string
+}
+
+// wantsHTML reports whether r comes from a browser rather than an API
+// client.
+func wantsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// browseTable renders a page of the table's rows. The query takes offset,
+// order (a column, prefixed with - for descending), and a filter per
+// column as w.COLUMN; pk=VALUE redirects to the record with that primary
+// key.
+func (s *Server) browseTable(rw http.ResponseWriter, r *http.Request, table *adt.Table, a *access) {
+	q := r.URL.Query()
+	if pk := q.Get("pk"); pk != "" {
+		s.browseKey(rw, r, table, a, pk)
+		return
+	}
+	page := &tablePage{Name: table.Name, Order: q.Get("order")}
+	values := url.Values{"limit": {strconv.Itoa(browsePageSize)}}
+	for _, k := range []string{"offset", "order"} {
+		if v := q.Get(k); v != "" {
+			values.Set(k, v)
+		}
+	}
+	for _, c := range table.Columns {
+		pc := pageColumn{Name: c.Name, Type: typeName(c), Masked: !a.allowed(c), Filter: q.Get("w." + c.Name)}
+		if pc.Filter != "" {
+			values.Add("where", filterExpr(c.Name, pc.Filter))
+			page.Filtered = true
+		}
+		if !pc.Masked {
+			order := c.Name
+			switch page.Order {
+			case c.Name:
+				pc.Sorted, order = "asc", "-"+c.Name
+			case "-" + c.Name:
+				pc.Sorted = "desc"
+			}
+			pc.Sort = pageURL(q, "order", order, "offset", "")
+		}
+		page.Columns = append(page.Columns, pc)
+	}
+
+	rq, err := parseRowsQuery(table, a, values)
+	if err != nil {
+		page.Error = err.Error()
+		rw.WriteHeader(http.StatusBadRequest)
+		render(rw, "db.tmpl", page)
+		return
+	}
+	recs, indexes, total, err := s.queryIndexed(table, rq)
+	if renderErr(rw, err) {
+		return
+	}
+	page.Total = total
+	for i, rec := range recs {
+		row := pageRow{Record: indexes[i] + 1, Link: recordURL(table.Name, indexes[i]+1)}
+		for _, c := range table.Columns {
+			row.Cells = append(row.Cells, s.cell(table.Name, c, a, rec[c.Name], true))
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	if len(recs) > 0 {
+		page.From, page.To = rq.offset+1, rq.offset+len(recs)
+	}
+	if rq.offset > 0 {
+		prev := rq.offset - rq.limit
+		if prev < 0 {
+			prev = 0
+		}
+		page.Prev = pageURL(q, "offset", strconv.Itoa(prev))
+	}
+	if rq.offset+len(recs) < total {
+		page.Next = pageURL(q, "offset", strconv.Itoa(rq.offset+len(recs)))
+	}
+	render(rw, "db.tmpl", page)
+}
+
+// browseKey redirects to the record of the table whose primary key is pk.
+func (s *Server) browseKey(rw http.ResponseWriter, r *http.Request, table *adt.Table, a *access, pk string) {
+	col, err := table.GetPK()
+	if err == nil && col == nil {
+		err = fmt.Errorf("%s has no primary key", tableName(table.Name))
+	}
+	var rq *rowsQuery
+	if err == nil {
+		rq, err = parseRowsQuery(table, a, url.Values{"limit": {"1"}, "where": {col.Name + "=" + pk}})
+	}
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		render(rw, "db.tmpl", &tablePage{Name: table.Name, Error: err.Error()})
+		return
+	}
+	_, indexes, _, err := s.queryIndexed(table, rq)
+	if renderErr(rw, err) {
+		return
+	}
+	if len(indexes) == 0 {
+		rw.WriteHeader(http.StatusNotFound)
+		render(rw, "db.tmpl", &tablePage{Name: table.Name, Error: fmt.Sprintf("no record with %s = %s", col.Name, pk)})
+		return
+	}
+	http.Redirect(rw, r, recordURL(table.Name, indexes[0]+1), http.StatusFound)
+}
+
+// browseRecord renders record n, counting from 1, of the table.
+func (s *Server) browseRecord(rw http.ResponseWriter, r *http.Request, table *adt.Table, a *access, n int, rec adt.Record) {
+	page := &recordPage{
+		Name:    table.Name,
+		Table:   "/dbs/" + url.PathEscape(table.Name),
+		Record:  n,
+		Records: int(table.RecordCount),
+	}
+	deleted, err := table.IsDeleted(n - 1)
+	if renderErr(rw, err) {
+		return
+	}
+	page.Deleted = deleted
+	for _, c := range table.Columns {
+		page.Fields = append(page.Fields, recordField{Name: c.Name, Type: typeName(c), Cell: s.cell(table.Name, c, a, rec[c.Name], false)})
+	}
+	if n > 1 {
+		page.Prev = recordURL(table.Name, n-1)
+	}
+	if n < page.Records {
+		page.Next = recordURL(table.Name, n+1)
+	}
+	if pk, err := table.GetPK(); err == nil && pk != nil && a.allowed(pk) {
+		key, _ := export.Format{}.Value(pk, rec[pk.Name])
+		visible, err := s.visible(r.Context())
+		if renderErr(rw, err) {
+			return
+		}
+		for _, db := range visible {
+			for column, to := range s.cfg[Table(db)] {
+				if strings.EqualFold(string(to), table.Name) {
+					page.Referrers = append(page.Referrers, referrer{
+						Table:  tableName(db),
+						Column: string(column),
+						Link:   "/dbs/" + url.PathEscape(db) + "?" + url.Values{"w." + string(column): {"=" + key}}.Encode(),
+					})
+				}
+			}
+		}
+		sort.Slice(page.Referrers, func(i, j int) bool {
+			if page.Referrers[i].Table != page.Referrers[j].Table {
+				return page.Referrers[i].Table < page.Referrers[j].Table
+			}
+			return page.Referrers[i].Column < page.Referrers[j].Column
+		})
+	}
+	render(rw, "record.tmpl", page)
+}
+
+// cell formats v, read from column c of the table file db. Memos are cut
+// short in previews.
+func (s *Server) cell(db string, c *adt.Column, a *access, v interface{}, preview bool) cell {
+	switch {
+	case !a.allowed(c):
+		return cell{Masked: true}
+	case v == nil:
+		return cell{Null: true}
+	}
+	if b, ok := v.([]byte); ok && c.Type == adt.ColumnTypeBlob {
+		return cell{Text: fmt.Sprintf("%d bytes", len(b))}
+	}
+	text, _ := export.Format{}.Value(c, v)
+	ce := cell{Text: text, Memo: c.Type == adt.ColumnTypeMemo}
+	if r := []rune(text); ce.Memo && preview && len(r) > memoPreview {
+		ce.Text, ce.Clipped = string(r[:memoPreview]), true
+	}
+	if to := s.cfg[Table(db)][Column(c.Name)]; to != "" {
+		ce.Link = "/dbs/" + url.PathEscape(string(to)) + "?" + url.Values{"pk": {text}}.Encode()
+	}
+	return ce
+}
+
+// filterExpr turns the filter typed into a column's box into a where
+// condition: a leading operator is used as is, otherwise the column must
+// contain the text.
+func filterExpr(column, filter string) string {
+	if strings.ContainsAny(filter[:1], "!=<>~") {
+		return column + filter
+	}
+	return column + "~" + filter
+}
+
+// typeName describes the type of c, with the length of character columns.
+func typeName(c *adt.Column) string {
+	name := strings.TrimPrefix(c.Type.String(), "ColumnType")
+	switch c.Type {
+	case adt.ColumnTypeCharacter, adt.ColumnTypeCiCharacter:
+		return fmt.Sprintf("%s(%d)", name, c.Length)
+	}
+	return name
+}
+
+// recordURL is the URL of record n, counting from 1, of the table file db.
+func recordURL(db string, n int) string {
+	return "/dbs/" + url.PathEscape(db) + "/" + strconv.Itoa(n)
+}
+
+// pageURL returns the query q, relative to the current page, with the
+// given key and value pairs set; empty values are removed.
+func pageURL(q url.Values, kv ...string) string {
+	u := url.Values{}
+	for k, v := range q {
+		u[k] = v
+	}
+	for i := 0; i < len(kv); i += 2 {
+		if kv[i+1] == "" {
+			u.Del(kv[i])
+		} else {
+			u.Set(kv[i], kv[i+1])
+		}
+	}
+	for k, v := range u {
+		if len(v) == 1 && v[0] == "" {
+			u.Del(k)
+		}
+	}
+	return "?" + u.Encode()
+}
+
+// srvStyle serves the browser's stylesheet.
+func (s *Server) srvStyle(rw http.ResponseWriter, r *http.Request) {
+	css, err := rice.MustFindBox("templates").Bytes("style.css")
+	if renderErr(rw, err) {
+		return
+	}
+	rw.Header().Set("Content-Type", "text/css; charset=utf-8")
+	rw.Write(css)
+}
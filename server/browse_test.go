@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"encoding/binary"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/server"
+)
+
+func TestBrowse(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob", "cy"}, []int32{30, 25, 41})
+	// PETS.ADM holds one memo, at block 1.
+	note := strings.Repeat("chases cars. ", 10)
+	memo := make([]byte, 8, 8+len(note))
+	if err := os.WriteFile(filepath.Join(dir, "PETS.ADM"), append(memo, note...), 0666); err != nil {
+		t.Fatal(err)
+	}
+	memoField := func(block uint32, n uint16) []byte {
+		b := make([]byte, 6)
+		binary.LittleEndian.PutUint32(b, block)
+		binary.LittleEndian.PutUint16(b[4:], n)
+		return b
+	}
+	pet := func(name string, owner int32, memo []byte) []byte {
+		return append(append([]byte(name), le32(owner)...), memo...)
+	}
+	writeADT(t, dir, "PETS.ADT", []*adt.Column{
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 5, Length: 4},
+		{Name: "OWNER", Type: adt.ColumnTypeInt, Offset: 9, Length: 4},
+		{Name: "NOTE", Type: adt.ColumnTypeMemo, Offset: 13, Length: 6},
+	}, [][]byte{
+		pet("rex ", 1, memoField(1, uint16(len(note)))),
+		pet("tom ", 2, memoField(1, 0)),
+	})
+	srv := server.New(server.Config{"PETS.ADT": {"OWNER": "PEOPLE.ADT"}}, dir, false)
+	h := srv.Handler()
+
+	get := func(url string, status int) string {
+		t.Helper()
+		r := httptest.NewRequest("GET", url, nil)
+		r.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if rec.Code != status {
+			t.Fatalf("%s: status %d, want %d\n%s", url, rec.Code, status, rec.Body)
+		}
+		if status == 302 {
+			return rec.Header().Get("Location")
+		}
+		if ct := rec.Header().Get("Content-Type"); status == 200 && !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("%s: Content-Type %q", url, ct)
+		}
+		return rec.Body.String()
+	}
+	contains := func(url, body string, want ...string) {
+		t.Helper()
+		for _, w := range want {
+			if !strings.Contains(body, w) {
+				t.Errorf("%s lacks %q", url, w)
+			}
+		}
+	}
+	order := func(url, body string, want ...string) {
+		t.Helper()
+		last := -1
+		for _, w := range want {
+			i := strings.Index(body, w)
+			if i < last {
+				t.Errorf("%s: %q out of order", url, w)
+			}
+			last = i
+		}
+	}
+
+	body := get("/dbs/PEOPLE.ADT", 200)
+	contains("people", body,
+		"rows 1–2 of 2",
+		`<a href="?order=AGE">AGE</a>`, "Character(6)", "AutoIncrement",
+		`<input name="w.NAME" value=""`,
+		`<a href="/dbs/PEOPLE.ADT/2">2</a>`)
+	if strings.Contains(body, "cy") {
+		t.Error("deleted record listed")
+	}
+
+	body = get("/dbs/PEOPLE.ADT?order=-AGE", 200)
+	contains("sorted", body, `<a href="?order=AGE">AGE</a> ▼`)
+	order("sorted", body, "ann", "bob")
+
+	body = get("/dbs/PEOPLE.ADT?w.AGE=%3E28&w.NAME=", 200)
+	contains("filtered", body, "rows 1–1 of 1 matching the filters", `value="&gt;28"`, "ann")
+	body = get("/dbs/PEOPLE.ADT?w.NAME=B", 200)
+	contains("contains", body, "rows 1–1 of 1", "bob")
+	body = get("/dbs/PEOPLE.ADT?w.AGE=%3Eold", 400)
+	contains("bad filter", body, `class="error"`)
+
+	body = get("/dbs/PETS.ADT", 200)
+	contains("pets", body,
+		`<a href="/dbs/PEOPLE.ADT?pk=1">1</a>`,
+		`<td class="memo">`+note[:80]+`<span class="clipped">…</span>`)
+
+	if loc := get("/dbs/PEOPLE.ADT?pk=2", 302); loc != "/dbs/PEOPLE.ADT/2" {
+		t.Errorf("pk=2 redirects to %q", loc)
+	}
+	get("/dbs/PEOPLE.ADT?pk=3", 404)
+
+	body = get("/dbs/PEOPLE.ADT/1", 200)
+	contains("record", body,
+		"PEOPLE.ADT record 1 of 3",
+		`<a href="/dbs/PETS.ADT?w.OWNER=%3D1">PETS by OWNER</a>`,
+		`<a href="/dbs/PEOPLE.ADT/2">next →</a>`)
+	contains("deleted record", get("/dbs/PEOPLE.ADT/3", 200), `<span class="deleted">deleted</span>`)
+	body = get("/dbs/PETS.ADT/1", 200)
+	contains("pet", body, `<td class="memo">`+note+`</td>`)
+	get("/dbs/PETS.ADT/3", 404)
+
+	css := httptest.NewRecorder()
+	h.ServeHTTP(css, httptest.NewRequest("GET", "/style.css", nil))
+	if css.Code != 200 || css.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Errorf("style.css: status %d, Content-Type %q", css.Code, css.Header().Get("Content-Type"))
+	}
+
+	// API clients still get JSON records.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/dbs/PETS.ADT/1", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("record without Accept: Content-Type %q", rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+)
+
+type Table string
+type Column string
+
+type ForeignKeys map[Column]Table
+
+type Config map[Table]ForeignKeys
+
+// LoadConfig reads a Config from the JSON file at path. An empty path
+// returns a nil Config.
+func LoadConfig(path string) (Config, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	result := Config{}
+	return result, json.NewDecoder(f).Decode(&result)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+// Formats of the export endpoint.
+const (
+	ExportNDJSON  = "ndjson"
+	ExportCSV     = "csv"
+	ExportParquet = "parquet"
+)
+
+// exportFlushRows is the number of rows written between flushes, so that
+// clients see a steady stream of chunks.
+const exportFlushRows = 1000
+
+// recordWriter is implemented by each of the export package's writers.
+type recordWriter interface {
+	Write(adt.Record) error
+	Close() error
+}
+
+// apiExport streams every active record of the table matching the where
+// conditions, with the selected fields, in one of the Export formats. The
+// response is chunked and stops as soon as the client goes away.
+func (s *Server) apiExport(rw http.ResponseWriter, r *http.Request, name string) {
+	table, a, ok := s.apiTable(rw, r, name)
+	if !ok {
+		return
+	}
+	defer table.Close()
+	q := r.URL.Query()
+	where, err := parseWhere(table, a, q)
+	if err != nil {
+		apiError(rw, http.StatusBadRequest, err)
+		return
+	}
+	var fields []string
+	if v := q.Get("fields"); v != "" {
+		fields = strings.Split(v, ",")
+	}
+	view, err := export.Project(table, fields)
+	if err != nil {
+		apiError(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	format := q.Get("format")
+	if format == "" {
+		format = ExportNDJSON
+	}
+	var contentType string
+	switch format {
+	case ExportNDJSON:
+		contentType = "application/x-ndjson"
+	case ExportCSV:
+		contentType = "text/csv; charset=utf-8"
+	case ExportParquet:
+		contentType = "application/vnd.apache.parquet"
+	default:
+		apiError(rw, http.StatusBadRequest, fmt.Errorf("unknown format %q: want %s, %s or %s", format, ExportNDJSON, ExportCSV, ExportParquet))
+		return
+	}
+
+	// The parquet writer sends its magic number as soon as it is made,
+	// so the headers are set first.
+	h := rw.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, tableName(table.Name), format))
+	h.Set("X-Content-Type-Options", "nosniff")
+	var w recordWriter
+	switch format {
+	case ExportNDJSON:
+		w, err = export.NewJSONWriter(rw, view, export.JSONOptions{})
+	case ExportCSV:
+		w, err = export.NewCSVWriter(rw, view, export.CSVOptions{Header: true})
+	case ExportParquet:
+		// Masked values are written as NULL, even in columns that
+		// otherwise can't hold one.
+		w, err = export.NewParquetWriter(rw, view, export.ParquetOptions{Optional: a.maskedColumns(view)})
+	}
+	if err != nil {
+		h.Del("Content-Disposition")
+		apiError(rw, http.StatusBadRequest, err)
+		return
+	}
+	ctx := r.Context()
+	n := 0
+	scanned := 0
+	defer func() { s.metrics.scanned(table.Name, scanned) }()
+	records := export.Range{Count: -1, Deleted: export.DeletedSkip}
+	err = records.Each(table, func(i int, rec adt.Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		scanned++
+		a.mask(table, rec)
+		if !matchAll(where, rec) {
+			return nil
+		}
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+		if n++; n%exportFlushRows == 0 {
+			return flush(w, rw, s.Limits.WriteTimeout)
+		}
+		return nil
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		// The status has been sent; abort the connection so that the
+		// client sees a truncated response rather than a complete one.
+		log.Printf("export %s: %v after %d rows", table.Name, err, n)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// flush pushes the rows buffered by w, if it buffers any, to the client,
+// and gives the response another timeout to write the next rows in.
+func flush(w recordWriter, rw http.ResponseWriter, timeout time.Duration) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	rc := http.NewResponseController(rw)
+	if timeout > 0 {
+		// Not every ResponseWriter supports deadlines; those that
+		// don't have none to extend.
+		rc.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
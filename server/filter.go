@@ -0,0 +1,213 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmc/adt"
+)
+
+// filter is one condition of a rows query's where parameter.
+type filter struct {
+	column *adt.Column
+	op     string
+	value  interface{}
+}
+
+// filterOps are the comparison operators of a where condition, longest
+// first so that "<=" isn't read as "<".
+var filterOps = []string{"!=", "<=", ">=", "=", "<", ">", "~"}
+
+// parseFilter parses a condition such as "NAME=bob", "ID>=10", "NOTE~late"
+// (contains) or "BORN=null" against the columns of t.
+func parseFilter(t *adt.Table, expr string) (*filter, error) {
+	i := strings.IndexAny(expr, "!=<>~")
+	if i <= 0 {
+		return nil, fmt.Errorf("bad condition %q: want COLUMN op VALUE", expr)
+	}
+	f := &filter{}
+	for _, op := range filterOps {
+		if strings.HasPrefix(expr[i:], op) {
+			f.op = op
+			break
+		}
+	}
+	if f.op == "" {
+		return nil, fmt.Errorf("bad operator in %q", expr)
+	}
+	name, literal := strings.TrimSpace(expr[:i]), strings.TrimSpace(expr[i+len(f.op):])
+	if f.column = findColumn(t, name); f.column == nil {
+		return nil, fmt.Errorf("no column %q", name)
+	}
+	if f.op == "~" {
+		f.value = strings.ToLower(literal)
+		return f, nil
+	}
+	if strings.EqualFold(literal, "null") {
+		if f.op != "=" && f.op != "!=" {
+			return nil, fmt.Errorf("%s: null only compares with = and !=", expr)
+		}
+		return f, nil
+	}
+	v, err := parseValue(f.column, literal)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", expr, err)
+	}
+	f.value = v
+	return f, nil
+}
+
+// match reports whether r satisfies the condition. NULL values match only
+// "=null" and "!=" conditions.
+func (f *filter) match(r adt.Record) bool {
+	v := r[f.column.Name]
+	if f.op == "~" {
+		return v != nil && strings.Contains(strings.ToLower(text(v)), f.value.(string))
+	}
+	if f.value == nil {
+		return (v == nil) == (f.op == "=")
+	}
+	if v == nil {
+		return f.op == "!="
+	}
+	c := compareValues(f.column, v, f.value)
+	switch f.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+func findColumn(t *adt.Table, name string) *adt.Column {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Layouts accepted for date and timestamp literals.
+var timeLayouts = []string{"2006-01-02", "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04:05.000"}
+
+// parseValue parses s into the Go type adt.ReadValue returns for c.
+func parseValue(c *adt.Column, s string) (interface{}, error) {
+	switch c.Type {
+	case adt.ColumnTypeCharacter, adt.ColumnTypeCiCharacter:
+		return s, nil
+	case adt.ColumnTypeMemo, adt.ColumnTypeBlob:
+		return []byte(s), nil
+	case adt.ColumnTypeBool:
+		return strconv.ParseBool(s)
+	case adt.ColumnTypeShortInt:
+		v, err := strconv.ParseInt(s, 10, 16)
+		return int16(v), err
+	case adt.ColumnTypeInt:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return int32(v), err
+	case adt.ColumnTypeAutoIncrement:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return uint32(v), err
+	case adt.ColumnTypeRowVersion:
+		return strconv.ParseUint(s, 10, 64)
+	case adt.ColumnTypeDouble, adt.ColumnTypeCurrency:
+		return strconv.ParseFloat(s, 64)
+	case adt.ColumnTypeDate, adt.ColumnTypeTimestamp, adt.ColumnTypeModTime:
+		for _, layout := range timeLayouts {
+			// Dates are read as local times; compare like with like.
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("bad date %q: want YYYY-MM-DD[THH:MM:SS]", s)
+	case adt.ColumnTypeTime:
+		t, err := time.Parse("15:04:05", s)
+		if err != nil {
+			return nil, fmt.Errorf("bad time %q: want HH:MM:SS", s)
+		}
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+	}
+	return nil, fmt.Errorf("can't compare %s columns", c.Type)
+}
+
+// compareValues orders two non-NULL values read from column c.
+func compareValues(c *adt.Column, a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		if c.Type == adt.ColumnTypeCiCharacter {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b.(string)))
+		}
+		return strings.Compare(a, b.(string))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	case bool:
+		switch b := b.(bool); {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		}
+		return 1
+	case time.Time:
+		b := b.(time.Time)
+		switch {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+		return 0
+	case time.Duration:
+		return compareNumbers(float64(a), float64(b.(time.Duration)))
+	case int16:
+		return compareNumbers(float64(a), float64(b.(int16)))
+	case int32:
+		return compareNumbers(float64(a), float64(b.(int32)))
+	case uint32:
+		return compareNumbers(float64(a), float64(b.(uint32)))
+	case uint64:
+		b := b.(uint64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case float64:
+		return compareNumbers(a, b.(float64))
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// text returns v as a string for substring matching.
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return adt.DecodeString(v)
+	}
+	return fmt.Sprint(v)
+}
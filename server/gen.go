@@ -0,0 +1,2 @@
+//go:generate rice embed-go
+package server
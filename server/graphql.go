@@ -0,0 +1,640 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/export"
+)
+
+// connection is the value of a paged list of rows in the GraphQL schema.
+type connection struct {
+	total, offset, limit int
+	rows                 []adt.Record
+}
+
+// relation is a foreign key from a column of one table to another, named
+// by their file names as in Config.
+type relation struct {
+	from   string
+	column string
+	to     string
+}
+
+// srvGraphQL runs a GraphQL query given as the query parameter of a GET or
+// as a JSON body of the form {"query", "variables", "operationName"}.
+func (s *Server) srvGraphQL(rw http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables"`
+		OperationName string                 `json:"operationName"`
+	}
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				apiError(rw, http.StatusBadRequest, fmt.Errorf("bad variables: %v", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apiError(rw, http.StatusBadRequest, fmt.Errorf("bad request body: %v", err))
+			return
+		}
+	default:
+		apiError(rw, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	schema, err := s.graphQLSchema(r.Context())
+	if err != nil {
+		apiError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.checkGraphQLQuery(schema, req.Query, req.OperationName, req.Variables); err != nil {
+		apiError(rw, http.StatusBadRequest, err)
+		return
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        r.Context(),
+	})
+	writeJSON(rw, http.StatusOK, result)
+}
+
+// graphQLSchema is a schema built from some of the table files of a
+// Server's directory.
+type graphQLSchema struct {
+	schema graphql.Schema
+	err    error
+}
+
+// graphQLSchema returns the schema of the tables in the server's directory
+// that the request's principal may read, so that introspection doesn't
+// reveal the others. Schemas are kept per set of readable tables, which a
+// Policy bounds, and built again whenever a table file is added, removed
+// or changed.
+func (s *Server) graphQLSchema(ctx context.Context) (graphql.Schema, error) {
+	key, err := s.tablesKey()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	dbs, err := s.visible(ctx)
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+	s.schemaMu.Lock()
+	defer s.schemaMu.Unlock()
+	if s.schemas == nil || s.schemasKey != key {
+		s.schemas = map[string]*graphQLSchema{}
+		s.schemasKey = key
+	}
+	visible := strings.Join(dbs, "\n")
+	if s.schemas[visible] == nil {
+		schema, err := s.buildGraphQLSchema(dbs)
+		s.schemas[visible] = &graphQLSchema{schema: schema, err: err}
+	}
+	return s.schemas[visible].schema, s.schemas[visible].err
+}
+
+// tablesKey describes the table files of the server's directory by name,
+// size and modification time.
+func (s *Server) tablesKey() (string, error) {
+	dbs, err := s.listdbs()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, db := range dbs {
+		fi, err := os.Stat(filepath.Join(s.path, db))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", db, fi.Size(), fi.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+// checkGraphQLQuery rejects a query nesting fields deeper than
+// Limits.GraphQLDepth or costing more than Limits.GraphQLCost, and one
+// whose fragments spread themselves, which graphql.Do recurses on until it
+// runs out of stack. Queries that don't parse are left to graphql.Do to
+// report.
+func (s *Server) checkGraphQLQuery(schema graphql.Schema, query, operation string, vars map[string]interface{}) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+	c := &graphQLCost{
+		schema:    &schema,
+		vars:      vars,
+		limits:    s.Limits,
+		fragments: map[string]*ast.FragmentDefinition{},
+		visiting:  map[string]bool{},
+	}
+	var ops []*ast.OperationDefinition
+	for _, d := range doc.Definitions {
+		switch d := d.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operation == "" || d.Name != nil && d.Name.Value == operation {
+				ops = append(ops, d)
+			}
+		}
+	}
+	for _, op := range ops {
+		c.walk(op.SelectionSet, schema.QueryType(), 1, 1)
+	}
+	if c.cycle != "" {
+		return fmt.Errorf("fragment %s spreads itself", c.cycle)
+	}
+	if max := s.Limits.GraphQLDepth; max > 0 && c.depth > max {
+		return fmt.Errorf("query nests fields more than %d deep", max)
+	}
+	if max := s.Limits.GraphQLCost; max > 0 && c.cost > float64(max) {
+		return fmt.Errorf("query may resolve more than %d fields", max)
+	}
+	return nil
+}
+
+// graphQLCost measures a GraphQL query: its depth is the deepest nesting of
+// its fields, its cost the number of fields it may resolve, the fields
+// below a paged one counting once per row of the page.
+type graphQLCost struct {
+	schema    *graphql.Schema
+	vars      map[string]interface{}
+	limits    Limits
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool // fragments being walked
+	cycle     string          // a fragment spreading itself
+	depth     int
+	cost      float64
+}
+
+// walk measures the selections of set, made on an object of type typ (nil
+// if unknown) at the given depth and resolved n times. Each field costs at
+// least 1, so that walking stops soon on queries over the limits.
+func (c *graphQLCost) walk(set *ast.SelectionSet, typ *graphql.Object, depth int, n float64) {
+	if set == nil {
+		return
+	}
+	for _, sel := range set.Selections {
+		if c.over() {
+			return
+		}
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if depth > c.depth {
+				c.depth = depth
+			}
+			c.cost += math.Max(n, 1)
+			var next *graphql.Object
+			m := n
+			if typ != nil {
+				if def := typ.Fields()[sel.Name.Value]; def != nil {
+					next, _ = graphql.GetNamed(def.Type).(*graphql.Object)
+					for _, a := range def.Args {
+						if a.Name() == "limit" {
+							m = n * float64(c.limit(sel))
+						}
+					}
+				}
+			}
+			c.walk(sel.SelectionSet, next, depth+1, m)
+		case *ast.InlineFragment:
+			c.walk(sel.SelectionSet, c.object(sel.TypeCondition, typ), depth, n)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			f := c.fragments[name]
+			if c.visiting[name] {
+				c.cycle = name
+				return
+			}
+			if f == nil {
+				continue
+			}
+			c.visiting[name] = true
+			c.walk(f.SelectionSet, c.object(f.TypeCondition, typ), depth, n)
+			delete(c.visiting, name)
+		}
+	}
+}
+
+func (c *graphQLCost) over() bool {
+	return c.cycle != "" ||
+		c.limits.GraphQLDepth > 0 && c.depth > c.limits.GraphQLDepth ||
+		c.limits.GraphQLCost > 0 && c.cost > float64(c.limits.GraphQLCost)
+}
+
+// object returns the object type named by a type condition, or typ if
+// there is none.
+func (c *graphQLCost) object(cond *ast.Named, typ *graphql.Object) *graphql.Object {
+	if cond == nil {
+		return typ
+	}
+	o, _ := c.schema.Type(cond.Name.Value).(*graphql.Object)
+	return o
+}
+
+// limit returns the page size asked for by the limit argument of f.
+func (c *graphQLCost) limit(f *ast.Field) int {
+	for _, a := range f.Arguments {
+		if a.Name.Value != "limit" {
+			continue
+		}
+		n := DefaultPageSize
+		switch v := a.Value.(type) {
+		case *ast.IntValue:
+			n, _ = strconv.Atoi(v.Value)
+		case *ast.Variable:
+			switch v := c.vars[v.Name.Value].(type) {
+			case float64:
+				n = int(v)
+			case int:
+				n = v
+			}
+		}
+		if n < 0 {
+			n = 0
+		}
+		return n
+	}
+	return DefaultPageSize
+}
+
+// buildGraphQLSchema generates a query field per table returning a page of
+// its rows. Each row has a field per column and, for every foreign key in
+// the Config, a field holding the referenced row and, on the referenced
+// table, a paged field listing the rows referring to it. Only the table
+// files dbs are included, and only the foreign keys between them.
+func (s *Server) buildGraphQLSchema(dbs []string) (graphql.Schema, error) {
+	tables := map[string]*adt.Table{}
+	for _, db := range dbs {
+		t, err := adt.TableFromPath(filepath.Join(s.path, db))
+		if err != nil {
+			return graphql.Schema{}, fmt.Errorf("%s: %v", db, err)
+		}
+		t.Close()
+		tables[db] = t
+	}
+	var relations []relation
+	for from, fks := range s.cfg {
+		for column, to := range fks {
+			if tables[string(from)] != nil && tables[string(to)] != nil {
+				relations = append(relations, relation{string(from), string(column), string(to)})
+			}
+		}
+	}
+
+	pks := map[string]*adt.Column{}
+	for _, rel := range relations {
+		var err error
+		if pks[rel.to], err = tables[rel.to].GetPK(); err != nil {
+			return graphql.Schema{}, fmt.Errorf("%s: %v", rel.to, err)
+		}
+	}
+
+	rowTypes := map[string]*graphql.Object{}
+	connTypes := map[string]*graphql.Object{}
+	for _, db := range dbs {
+		db, table := db, tables[db]
+		rowTypes[db] = graphql.NewObject(graphql.ObjectConfig{
+			Name:        identifier(tableName(db)),
+			Description: "A row of " + tableName(db),
+			Fields: graphql.FieldsThunk(func() graphql.Fields {
+				fields := graphql.Fields{}
+				for _, c := range table.Columns {
+					// Under a Policy any column may be masked.
+					fields[graphQLName(c.Name)] = columnField(c, s.Policy == nil && !c.Nullable())
+				}
+				for _, rel := range relations {
+					if rel.from == db {
+						fields[graphQLName(rel.column+"_"+tableName(rel.to))] = s.referenceField(rel, rowTypes[rel.to])
+					}
+					if rel.to == db {
+						fields[graphQLName(tableName(rel.from)+"_by_"+rel.column)] = s.referrersField(rel, pks[db], connTypes[rel.from])
+					}
+				}
+				return fields
+			}),
+		})
+		connTypes[db] = graphql.NewObject(graphql.ObjectConfig{
+			Name:        identifier(tableName(db)) + "Connection",
+			Description: "A page of rows of " + tableName(db),
+			Fields: graphql.Fields{
+				"total": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "number of rows matching the query",
+					Resolve:     func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*connection).total, nil },
+				},
+				"offset": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*connection).offset, nil },
+				},
+				"limit": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*connection).limit, nil },
+				},
+				"rows": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(rowTypes[db]))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*connection).rows, nil
+					},
+				},
+			},
+		})
+	}
+
+	query := graphql.Fields{}
+	for _, db := range dbs {
+		db := db
+		query[graphQLName(tableName(db))] = &graphql.Field{
+			Type:        graphql.NewNonNull(connTypes[db]),
+			Description: "Page through the active records of " + tableName(db),
+			Args:        pageArgs(),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return s.resolveRows(p, db, "")
+			},
+		}
+	}
+	if len(query) == 0 {
+		query["tables"] = &graphql.Field{
+			Type:    graphql.NewList(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) { return []string{}, nil },
+		}
+	}
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: query}),
+	})
+}
+
+func pageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: DefaultPageSize},
+		"where": &graphql.ArgumentConfig{
+			Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+			Description: "conditions COLUMN op VALUE, op one of = != < <= > >= ~ (contains)",
+		},
+		"order": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "comma separated columns, prefixed with - for descending",
+		},
+	}
+}
+
+// resolveRows returns a connection of the rows of db selected by the
+// field's arguments and, if extra isn't empty, the condition extra.
+func (s *Server) resolveRows(p graphql.ResolveParams, db, extra string) (interface{}, error) {
+	if err := p.Context.Err(); err != nil {
+		return nil, err
+	}
+	table, err := adt.TableFromPath(filepath.Join(s.path, db))
+	if err != nil {
+		return nil, err
+	}
+	defer table.Close()
+	a, ok := s.access(p.Context, db, table)
+	if !ok {
+		return nil, fmt.Errorf("%s: %v", tableName(db), errForbidden)
+	}
+	q := rowsArgs(p.Args)
+	if extra != "" {
+		q.Add("where", extra)
+	}
+	rq, err := parseRowsQuery(table, a, q)
+	if err != nil {
+		return nil, err
+	}
+	rows, total, err := s.queryRows(table, rq)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{total: total, offset: rq.offset, limit: rq.limit, rows: rows}, nil
+}
+
+// rowsArgs turns the arguments of a paged field into the query of a rows
+// request.
+func rowsArgs(args map[string]interface{}) url.Values {
+	q := url.Values{}
+	if v, ok := args["offset"].(int); ok {
+		q.Set("offset", strconv.Itoa(v))
+	}
+	if v, ok := args["limit"].(int); ok {
+		q.Set("limit", strconv.Itoa(v))
+	}
+	if v, ok := args["order"].(string); ok {
+		q.Set("order", v)
+	}
+	if v, ok := args["where"].([]interface{}); ok {
+		for _, w := range v {
+			q.Add("where", w.(string))
+		}
+	}
+	return q
+}
+
+// referenceField resolves the row of rel.to whose primary key is the value
+// of rel.column, or null if there is none.
+func (s *Server) referenceField(rel relation, to *graphql.Object) *graphql.Field {
+	return &graphql.Field{
+		Type:        to,
+		Description: fmt.Sprintf("The %s row referenced by %s", tableName(rel.to), rel.column),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			v := p.Source.(adt.Record)[rel.column]
+			if v == nil {
+				return nil, nil
+			}
+			r, err := s.lookupRecord(p.Context, Table(rel.to), v)
+			if err == errNoRecord {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return adt.Record(r), nil
+		},
+	}
+}
+
+// referrersField resolves a page of the rows of rel.from whose rel.column
+// refers to the row's primary key pk, nil if rel.to has none.
+func (s *Server) referrersField(rel relation, pk *adt.Column, from *graphql.Object) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewNonNull(from),
+		Description: fmt.Sprintf("The %s rows referring to this row by %s", tableName(rel.from), rel.column),
+		Args:        pageArgs(),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if pk == nil {
+				return nil, fmt.Errorf("%s has no primary key", tableName(rel.to))
+			}
+			refs, err := s.referrers(p, rel)
+			if err != nil {
+				return nil, err
+			}
+			conn := &connection{offset: refs.rq.offset, limit: refs.rq.limit}
+			if v := p.Source.(adt.Record)[pk.Name]; v != nil {
+				rows := refs.groups[fmt.Sprint(v)]
+				conn.total = len(rows)
+				if conn.offset < len(rows) {
+					end := conn.offset + conn.limit
+					if end > len(rows) {
+						end = len(rows)
+					}
+					conn.rows = rows[conn.offset:end]
+				}
+			}
+			return conn, nil
+		},
+	}
+}
+
+// referrerRows are the rows of a table selected by the arguments of a
+// referrer field, grouped by the value of the column referring to other
+// rows.
+type referrerRows struct {
+	rq     *rowsQuery
+	groups map[string][]adt.Record
+}
+
+// referrers returns the rows of rel.from selected by the field's arguments,
+// grouped by rel.column. They are read once per request, rather than once
+// per referenced row.
+func (s *Server) referrers(p graphql.ResolveParams, rel relation) (*referrerRows, error) {
+	key := fmt.Sprint(rel, p.Args)
+	if refs, ok := info(p.Context).lookupReferrers(key); ok {
+		return refs, nil
+	}
+	if err := p.Context.Err(); err != nil {
+		return nil, err
+	}
+	table, err := adt.TableFromPath(filepath.Join(s.path, rel.from))
+	if err != nil {
+		return nil, err
+	}
+	defer table.Close()
+	a, ok := s.access(p.Context, rel.from, table)
+	if !ok {
+		return nil, fmt.Errorf("%s: %v", tableName(rel.from), errForbidden)
+	}
+	rq, err := parseRowsQuery(table, a, rowsArgs(p.Args))
+	if err != nil {
+		return nil, err
+	}
+	c := findColumn(table, rel.column)
+	if c == nil {
+		return nil, fmt.Errorf("%s has no column %s", tableName(rel.from), rel.column)
+	}
+	if !a.allowed(c) {
+		return nil, fmt.Errorf("column %s is masked", c.Name)
+	}
+	all := *rq
+	all.offset, all.limit = 0, math.MaxInt
+	rows, _, _, err := s.queryIndexed(table, &all)
+	if err != nil {
+		return nil, err
+	}
+	refs := &referrerRows{rq: rq, groups: map[string][]adt.Record{}}
+	for _, r := range rows {
+		if v := r[c.Name]; v != nil {
+			k := fmt.Sprint(v)
+			refs.groups[k] = append(refs.groups[k], r)
+		}
+	}
+	info(p.Context).storeReferrers(key, refs)
+	return refs, nil
+}
+
+// columnField resolves the value of c in a row, which is never NULL if
+// nonNull is set.
+func columnField(c *adt.Column, nonNull bool) *graphql.Field {
+	var typ graphql.Output
+	switch c.Type {
+	case adt.ColumnTypeBool:
+		typ = graphql.Boolean
+	case adt.ColumnTypeShortInt, adt.ColumnTypeInt, adt.ColumnTypeAutoIncrement:
+		typ = graphql.Int
+	case adt.ColumnTypeDouble, adt.ColumnTypeCurrency:
+		typ = graphql.Float
+	default:
+		typ = graphql.String
+	}
+	if nonNull {
+		typ = graphql.NewNonNull(typ)
+	}
+	return &graphql.Field{
+		Type:        typ,
+		Description: strings.TrimPrefix(c.Type.String(), "ColumnType"),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLValue(c, p.Source.(adt.Record)[c.Name]), nil
+		},
+	}
+}
+
+// graphQLValue converts a value read from c to the type of its field,
+// formatting dates and times like the JSON API.
+func graphQLValue(c *adt.Column, v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		if c.Type == adt.ColumnTypeBlob {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+		return adt.DecodeString(v)
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case uint32:
+		return int(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+		return v
+	case time.Duration:
+		return adt.FormatDuration(v)
+	case time.Time:
+		if c.Type == adt.ColumnTypeDate {
+			return v.Format(export.JSONDateLayout)
+		}
+		return v.Format(export.JSONTimestampLayout)
+	}
+	return v
+}
+
+var invalidName = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+// graphQLName makes name a valid GraphQL name.
+func graphQLName(name string) string {
+	name = invalidName.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,186 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/server"
+)
+
+func TestGraphQL(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob"}, []int32{30, 25})
+	writeADT(t, dir, "PETS.ADT", []*adt.Column{
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 5, Length: 4},
+		{Name: "OWNER", Type: adt.ColumnTypeInt, Offset: 9, Length: 4},
+	}, [][]byte{
+		append([]byte("rex "), le32(1)...),
+		append([]byte("tom "), le32(2)...),
+		append([]byte("kit "), le32(1)...),
+	})
+	cfg := server.Config{"PETS.ADT": {"OWNER": "PEOPLE.ADT"}}
+	h := server.New(cfg, dir, false).Handler()
+
+	post := func(q string, vars map[string]interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{"query": q, "variables": vars})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+		return rec
+	}
+	query := func(q string) (data interface{}, errs []interface{}) {
+		t.Helper()
+		rec := post(q, nil)
+		var resp struct {
+			Data   interface{}
+			Errors []interface{}
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%v: %s", err, rec.Body)
+		}
+		return resp.Data, resp.Errors
+	}
+	roundTrip := func(s string) interface{} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct{ query, want string }{
+		{`{ PETS(where: ["NAME~t"], order: "-NAME") { total rows { NAME OWNER_PEOPLE { NAME AGE } } } }`,
+			`{"PETS": {"total": 2, "rows": [
+				{"NAME": "tom", "OWNER_PEOPLE": {"NAME": "bob", "AGE": 25}},
+				{"NAME": "kit", "OWNER_PEOPLE": {"NAME": "ann", "AGE": 30}}]}}`},
+		{`{ PEOPLE(limit: 1) { total limit rows { ID PETS_by_OWNER(limit: 1) { total rows { NAME } } } } }`,
+			`{"PEOPLE": {"total": 2, "limit": 1, "rows": [
+				{"ID": 1, "PETS_by_OWNER": {"total": 2, "rows": [{"NAME": "rex"}]}}]}}`},
+		{`{ PEOPLE { rows { ID PETS_by_OWNER(offset: 1) { total rows { NAME } } } } }`,
+			`{"PEOPLE": {"rows": [
+				{"ID": 1, "PETS_by_OWNER": {"total": 2, "rows": [{"NAME": "kit"}]}},
+				{"ID": 2, "PETS_by_OWNER": {"total": 1, "rows": []}}]}}`},
+	}
+	for _, tt := range tests {
+		data, errs := query(tt.query)
+		if len(errs) > 0 {
+			t.Errorf("%s: %v", tt.query, errs)
+			continue
+		}
+		if want := roundTrip(tt.want); !reflect.DeepEqual(data, want) {
+			t.Errorf("%s:\ngot  %v\nwant %v", tt.query, data, want)
+		}
+	}
+
+	if _, errs := query(`{ PETS(where: ["HEIGHT>1"]) { total } }`); len(errs) == 0 {
+		t.Error("unknown column in where: no error")
+	}
+
+	// The referrer fields of all people read PETS once.
+	s := server.New(cfg, dir, false)
+	h = s.Handler()
+	if _, errs := query(`{ PEOPLE { rows { PETS_by_OWNER { total } } } }`); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	metrics := httptest.NewRecorder()
+	h.ServeHTTP(metrics, httptest.NewRequest("GET", "/metrics", nil))
+	if want := `adt_rows_scanned_total{table="PETS"} 3` + "\n"; !strings.Contains(metrics.Body.String(), want) {
+		t.Errorf("metrics lack %q", want)
+	}
+
+	// The schema follows the tables in the directory.
+	writeADT(t, dir, "TOYS.ADT", []*adt.Column{
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 5, Length: 4},
+	}, [][]byte{[]byte("ball")})
+	if data, errs := query(`{ TOYS { total } }`); len(errs) > 0 || !reflect.DeepEqual(data, roundTrip(`{"TOYS": {"total": 1}}`)) {
+		t.Errorf("new table: %v %v", data, errs)
+	}
+
+	s.Limits.GraphQLDepth = 3
+	limited := []struct {
+		query string
+		vars  map[string]interface{}
+	}{
+		{`{ PEOPLE { rows { PETS_by_OWNER { total } } } }`, nil},
+		{`{ ...p } fragment p on Query { PEOPLE { rows { ...r } } } fragment r on PEOPLE { PETS_by_OWNER { total } }`, nil},
+		{`{ PETS(limit: 1000) { rows { OWNER_PEOPLE { PETS_by_OWNER(limit: 1000) { total } } } } }`, nil},
+		{`query($n: Int) { PETS(limit: $n) { rows { OWNER_PEOPLE { PETS_by_OWNER(limit: $n) { total } } } } }`, map[string]interface{}{"n": 1000}},
+		{`{ ...a } fragment a on Query { PEOPLE { total } ...b } fragment b on Query { ...a }`, nil},
+	}
+	for i, tt := range limited {
+		if i == 2 {
+			s.Limits.GraphQLDepth = 0
+		}
+		if rec := post(tt.query, tt.vars); rec.Code != 400 {
+			t.Errorf("%s: status %d, want 400\n%s", tt.query, rec.Code, rec.Body)
+		}
+	}
+}
+
+// TestGraphQLPolicy checks that each principal's schema holds only the
+// tables it may read, and that a table name GraphQL can't spell as a type
+// name doesn't break the schema.
+func TestGraphQLPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob"}, []int32{30, 25})
+	writeADT(t, dir, "CAFÉ.ADT", []*adt.Column{
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 5, Length: 4},
+	}, [][]byte{[]byte("flat")})
+	srv := server.New(nil, dir, false)
+	srv.Auth = []server.Authenticator{server.BearerTokens{"t0ken": "alice", "s3cret": "carol"}}
+	srv.Policy = server.Policy{
+		"alice": {{Table: "PEOPLE"}},
+		"carol": {{Table: "*"}},
+	}
+	h := srv.Handler()
+
+	query := func(token, q string) interface{} {
+		t.Helper()
+		r := httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(q), nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		var resp struct {
+			Data   interface{}
+			Errors []interface{}
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Errors) > 0 {
+			t.Fatalf("%s: %v %v\n%s", q, err, resp.Errors, rec.Body)
+		}
+		return resp.Data
+	}
+	fields := func(token string) []string {
+		t.Helper()
+		var resp struct {
+			Schema struct {
+				QueryType struct{ Fields []struct{ Name string } }
+			} `json:"__schema"`
+		}
+		buf, _ := json.Marshal(query(token, `{ __schema { queryType { fields { name } } } }`))
+		json.Unmarshal(buf, &resp)
+		var names []string
+		for _, f := range resp.Schema.QueryType.Fields {
+			names = append(names, f.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	if got, want := fields("t0ken"), []string{"PEOPLE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("alice's query fields = %q, want %q", got, want)
+	}
+	if got, want := fields("s3cret"), []string{"CAF_", "PEOPLE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("carol's query fields = %q, want %q", got, want)
+	}
+	var want interface{}
+	json.Unmarshal([]byte(`{"CAF_": {"total": 1, "rows": [{"NAME": "flat"}]}, "PEOPLE": {"total": 2}}`), &want)
+	if got := query("s3cret", `{ CAF_ { total rows { NAME } } PEOPLE { total } }`); !reflect.DeepEqual(got, want) {
+		t.Errorf("carol's query = %v, want %v", got, want)
+	}
+}
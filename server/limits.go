@@ -0,0 +1,192 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limits bounds what a Server spends on its clients. Zero values disable
+// the corresponding limit.
+type Limits struct {
+	ReadTimeout     time.Duration // reading a request, body included
+	WriteTimeout    time.Duration // writing a response; exports extend it at each flush
+	IdleTimeout     time.Duration // waiting for the next request on a keep-alive connection
+	ShutdownTimeout time.Duration // waiting for requests in flight on shutdown
+
+	MaxConcurrent int     // requests served at once; others get a 503
+	Rate          float64 // requests per second per client address; others get a 429
+	Burst         int     // requests a client may make at once above Rate
+	MaxBodyBytes  int64   // request body size
+
+	GraphQLDepth int // nesting of fields in a GraphQL query
+	GraphQLCost  int // fields a GraphQL query may resolve, see graphQLCost
+}
+
+// DefaultLimits are the Limits of a new Server. They don't limit the rate
+// of requests.
+var DefaultLimits = Limits{
+	ReadTimeout:     30 * time.Second,
+	WriteTimeout:    time.Minute,
+	IdleTimeout:     2 * time.Minute,
+	ShutdownTimeout: 30 * time.Second,
+	MaxConcurrent:   100,
+	MaxBodyBytes:    1 << 20,
+	GraphQLDepth:    10,
+	GraphQLCost:     100000,
+}
+
+// clientIdle is how long the rate limiter remembers a client that made no
+// requests.
+const clientIdle = 10 * time.Minute
+
+// securityHeaders sets headers keeping browsers from sniffing, framing or
+// leaking the server's responses.
+func securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		h := rw.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// cors lets the browser pages of s's Origins read its responses, and
+// answers OPTIONS requests itself, before they need to authenticate.
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		h := rw.Header()
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && s.allowOrigin(origin)
+		if len(s.Origins) > 0 {
+			h.Add("Vary", "Origin")
+		}
+		if allowed {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if r.Method != http.MethodOptions {
+			next.ServeHTTP(rw, r)
+			return
+		}
+		h.Set("Allow", "GET, HEAD, POST, OPTIONS")
+		if allowed && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", "GET, HEAD, POST")
+			h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			h.Set("Access-Control-Max-Age", "600")
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowOrigin reports whether origin is one of s's Origins.
+func (s *Server) allowOrigin(origin string) bool {
+	for _, o := range s.Origins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// limit applies the request limits of l to next.
+func (l Limits) limit(next http.Handler) http.Handler {
+	var sem chan struct{}
+	if l.MaxConcurrent > 0 {
+		sem = make(chan struct{}, l.MaxConcurrent)
+	}
+	var clients *rateLimiter
+	if l.Rate > 0 {
+		clients = newRateLimiter(rate.Limit(l.Rate), l.Burst)
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if clients != nil {
+			if wait, ok := clients.allow(clientAddr(r), time.Now()); !ok {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				apiError(rw, http.StatusTooManyRequests, errors.New("too many requests"))
+				return
+			}
+		}
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			default:
+				rw.Header().Set("Retry-After", "1")
+				apiError(rw, http.StatusServiceUnavailable, errors.New("server busy"))
+				return
+			}
+		}
+		if l.MaxBodyBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(rw, r.Body, l.MaxBodyBytes)
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// clientAddr returns the IP address r came from.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter keeps a token bucket per client, forgetting clients idle for
+// longer than clientIdle.
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	clients map[string]*client
+	swept   time.Time
+}
+
+type client struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+func newRateLimiter(limit rate.Limit, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{limit: limit, burst: burst, clients: map[string]*client{}, swept: time.Now()}
+}
+
+// allow reports whether addr may make a request at now, or else how long
+// it should wait.
+func (rl *rateLimiter) allow(addr string, now time.Time) (time.Duration, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if now.Sub(rl.swept) > clientIdle {
+		for a, c := range rl.clients {
+			if now.Sub(c.seen) > clientIdle {
+				delete(rl.clients, a)
+			}
+		}
+		rl.swept = now
+	}
+	c, ok := rl.clients[addr]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.clients[addr] = c
+	}
+	c.seen = now
+	res := c.limiter.ReserveN(now, 1)
+	if wait := res.DelayFrom(now); wait > 0 {
+		res.CancelAt(now)
+		return wait, false
+	}
+	return 0, true
+}
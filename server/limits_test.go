@@ -0,0 +1,108 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tmc/adt/server"
+)
+
+func TestLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann"}, []int32{30})
+	srv := server.New(nil, dir, false)
+	srv.Auth = []server.Authenticator{server.BearerTokens{"t0ken": "alice"}}
+	srv.Origins = []string{"https://app.example"}
+	srv.Limits.Rate = 1
+	srv.Limits.Burst = 3
+	h := srv.Handler()
+
+	do := func(method, origin, addr string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, "/api/tables", nil)
+		r.RemoteAddr = addr
+		r.Header.Set("Authorization", "Bearer t0ken")
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			r.Header.Del("Authorization")
+			r.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := do("GET", "https://app.example", "192.0.2.1:1000")
+	if rec.Code != 200 || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example" {
+		t.Errorf("allowed origin: status %d, headers %v", rec.Code, rec.Header())
+	}
+	for _, h := range []string{"X-Content-Type-Options", "X-Frame-Options", "Content-Security-Policy", "Referrer-Policy"} {
+		if rec.Header().Get(h) == "" {
+			t.Errorf("no %s header", h)
+		}
+	}
+	if rec := do("GET", "https://evil.example", "192.0.2.1:1000"); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other origin allowed: %v", rec.Header())
+	}
+	// Preflight requests carry no credentials.
+	rec = do("OPTIONS", "https://app.example", "192.0.2.2:1000")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Headers") == "" {
+		t.Errorf("preflight: status %d, headers %v", rec.Code, rec.Header())
+	}
+
+	// 192.0.2.1 has used two of its three requests.
+	if rec := do("GET", "", "192.0.2.1:1001"); rec.Code != 200 {
+		t.Errorf("third request: status %d", rec.Code)
+	}
+	rec = do("GET", "", "192.0.2.1:1002")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("fourth request: status %d, headers %v", rec.Code, rec.Header())
+	}
+	if rec := do("GET", "", "192.0.2.3:1000"); rec.Code != 200 {
+		t.Errorf("other client: status %d", rec.Code)
+	}
+}
+
+func TestServeContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	srv := server.New(nil, t.TempDir(), false)
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ServeContext(ctx, addr, "", "") }()
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + "/api/tables"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Errorf("status %d", resp.StatusCode)
+	}
+	cancel()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("ServeContext: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeContext didn't return after shutdown")
+	}
+}
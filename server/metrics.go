@@ -0,0 +1,112 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics are the Prometheus metrics of a Server, served at /metrics. Next
+// to its own they include the Go runtime's and the process's, such as
+// process_open_fds.
+type metrics struct {
+	registry    *prometheus.Registry
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	inFlight    prometheus.Gauge
+	rowsScanned *prometheus.CounterVec
+	lookups     *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adt_http_requests_total",
+			Help: "HTTP requests by route, table, method and status code.",
+		}, []string{"route", "table", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "adt_http_request_duration_seconds",
+			Help:    "Time taken to serve HTTP requests by route and table.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "table"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "adt_http_requests_in_flight",
+			Help: "HTTP requests being served.",
+		}),
+		rowsScanned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adt_rows_scanned_total",
+			Help: "Records read from tables to answer requests, by table.",
+		}, []string{"table"}),
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "adt_record_lookups_total",
+			Help: "Lookups of related records by primary key, by whether the request's cache held them.",
+		}, []string{"result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.duration, m.inFlight, m.rowsScanned, m.lookups,
+	)
+	return m
+}
+
+// srvMetrics serves the metrics in the Prometheus text format.
+func (s *Server) srvMetrics(rw http.ResponseWriter, r *http.Request) {
+	promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}).ServeHTTP(rw, r)
+}
+
+// observe records a finished request.
+func (m *metrics) observe(info *requestInfo, method string, status int, seconds float64) {
+	table := info.tableName()
+	m.requests.WithLabelValues(info.route, table, method, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(info.route, table).Observe(seconds)
+}
+
+// scanned records that n records of the table file db were read.
+func (m *metrics) scanned(db string, n int) {
+	if n > 0 {
+		m.rowsScanned.WithLabelValues(tableName(db)).Add(float64(n))
+	}
+}
+
+// lookup records a lookup of a related record.
+func (m *metrics) lookup(hit bool) {
+	if hit {
+		m.lookups.WithLabelValues("hit").Inc()
+	} else {
+		m.lookups.WithLabelValues("miss").Inc()
+	}
+}
+
+// route returns the pattern of the handler serving path, keeping table
+// names and record numbers out of metric labels.
+func route(path string) string {
+	switch path {
+	case "/", "/openapi.json", "/graphql", "/metrics", "/style.css":
+		return path
+	}
+	if name := strings.TrimPrefix(path, "/dbs/"); name != path {
+		if strings.Contains(name, "/") {
+			return "/dbs/{name}/{record}"
+		}
+		return "/dbs/{name}"
+	}
+	if name := strings.TrimPrefix(path, "/api/"); name != path {
+		parts := strings.Split(strings.Trim(name, "/"), "/")
+		if len(parts) == 1 && parts[0] == "tables" {
+			return "/api/tables"
+		}
+		if len(parts) == 3 && parts[0] == "tables" {
+			switch parts[2] {
+			case "schema", "rows", "export":
+				return "/api/tables/{name}/" + parts[2]
+			}
+		}
+	}
+	return "other"
+}
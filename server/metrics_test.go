@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmc/adt"
+	"github.com/tmc/adt/server"
+)
+
+func TestMetrics(t *testing.T) {
+	dir := t.TempDir()
+	writeTable(t, dir, []string{"ann", "bob"}, []int32{30, 25})
+	writeADT(t, dir, "PETS.ADT", []*adt.Column{
+		{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 5, Length: 4},
+		{Name: "OWNER", Type: adt.ColumnTypeInt, Offset: 9, Length: 4},
+	}, [][]byte{
+		append([]byte("rex "), le32(1)...),
+		append([]byte("tom "), le32(2)...),
+		append([]byte("kit "), le32(1)...),
+	})
+	srv := server.New(server.Config{"PETS.ADT": {"OWNER": "PEOPLE.ADT"}}, dir, false)
+	var logs bytes.Buffer
+	srv.AccessLog = slog.New(slog.NewJSONHandler(&logs, nil))
+	h := srv.Handler()
+
+	do := func(method, url, body, id string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		if id != "" {
+			r.Header.Set("X-Request-Id", id)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	// rex and kit share an owner, who is looked up once.
+	do("POST", "/graphql", `{"query": "{ PETS { rows { OWNER_PEOPLE { NAME } } } }"}`, "")
+	rows := do("GET", "/api/tables/PEOPLE/rows", "", "trace-1")
+	do("GET", "/api/tables/NOPE/rows", "", "")
+
+	rec := do("GET", "/metrics", "", "")
+	if rec.Code != 200 {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	for _, want := range []string{
+		`adt_http_requests_total{code="200",method="POST",route="/graphql",table=""} 1`,
+		`adt_http_requests_total{code="200",method="GET",route="/api/tables/{name}/rows",table="PEOPLE"} 1`,
+		`adt_http_requests_total{code="404",method="GET",route="/api/tables/{name}/rows",table=""} 1`,
+		`adt_http_request_duration_seconds_count{route="/graphql",table=""} 1`,
+		`adt_record_lookups_total{result="hit"} 1`,
+		`adt_record_lookups_total{result="miss"} 2`,
+		`adt_rows_scanned_total{table="PETS"} 3`,
+		// 2 and 1 records to find ann and bob, then the page of 2.
+		`adt_rows_scanned_total{table="PEOPLE"} 5`,
+		`adt_http_requests_in_flight 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), want+"\n") {
+			t.Errorf("metrics lack %s", want)
+		}
+	}
+
+	if id := rows.Header().Get("X-Request-Id"); id != "trace-1" {
+		t.Errorf("X-Request-Id = %q, want the client's", id)
+	}
+	if id := do("GET", "/", "", "bad id!").Header().Get("X-Request-Id"); id == "" || id == "bad id!" {
+		t.Errorf("X-Request-Id = %q, want a new one", id)
+	}
+
+	var entries []map[string]interface{}
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var e map[string]interface{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("got %d log entries, want 5", len(entries))
+	}
+	e := entries[1]
+	if e["id"] != "trace-1" || e["route"] != "/api/tables/{name}/rows" || e["table"] != "PEOPLE" ||
+		e["status"] != 200.0 || e["uri"] != "/api/tables/PEOPLE/rows" || e["bytes"].(float64) == 0 {
+		t.Errorf("log entry = %v", e)
+	}
+	if e := entries[2]; e["status"] != 404.0 || e["table"] != nil {
+		t.Errorf("log entry = %v", e)
+	}
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/adt"
+)
+
+// object is a JSON object of an OpenAPI document.
+type object map[string]interface{}
+
+// srvOpenAPI serves an OpenAPI 3 description of the JSON API, with a row
+// schema for each table in the directory.
+func (s *Server) srvOpenAPI(rw http.ResponseWriter, r *http.Request) {
+	doc, err := s.openAPI(r.Context())
+	if err != nil {
+		apiError(rw, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, doc)
+}
+
+// openAPI describes the tables the request's principal may read. Under a
+// Policy every column is nullable, since masked columns read as NULL.
+func (s *Server) openAPI(ctx context.Context) (object, error) {
+	dbs, err := s.visible(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(dbs))
+	for i, db := range dbs {
+		names[i] = tableName(db)
+	}
+	schemas := object{
+		"Error": object{
+			"type":       "object",
+			"properties": object{"error": object{"type": "string"}},
+			"required":   []string{"error"},
+		},
+		"TableInfo": object{
+			"type": "object",
+			"properties": object{
+				"name":    object{"type": "string", "enum": names},
+				"records": object{"type": "integer"},
+				"columns": object{"type": "integer"},
+				"schema":  object{"type": "string", "description": "URL of the table's schema"},
+				"rows":    object{"type": "string", "description": "URL of the table's rows"},
+			},
+			"required": []string{"name", "records", "columns", "schema", "rows"},
+		},
+		"TableSchema": object{
+			"type": "object",
+			"properties": object{
+				"table":       object{"type": "string"},
+				"records":     object{"type": "integer"},
+				"primary_key": object{"type": "array", "items": object{"type": "string"}},
+				"columns": object{"type": "array", "items": object{
+					"type": "object",
+					"properties": object{
+						"name":     object{"type": "string"},
+						"type":     object{"type": "string"},
+						"length":   object{"type": "integer"},
+						"decimals": object{"type": "integer"},
+						"nullable": object{"type": "boolean"},
+					},
+				}},
+			},
+		},
+	}
+	errorResponse := object{
+		"description": "error",
+		"content":     jsonContent(ref("Error")),
+	}
+	paths := object{
+		"/api/tables": object{"get": object{
+			"operationId": "listTables",
+			"summary":     "List the tables",
+			"responses": object{
+				"200": object{
+					"description": "the tables",
+					"content": jsonContent(object{
+						"type":       "object",
+						"properties": object{"tables": object{"type": "array", "items": ref("TableInfo")}},
+					}),
+				},
+			},
+		}},
+		"/api/tables/{name}/schema": object{"get": object{
+			"operationId": "getTableSchema",
+			"summary":     "Describe the columns of a table",
+			"parameters": []object{{
+				"name": "name", "in": "path", "required": true,
+				"schema": object{"type": "string", "enum": names},
+			}},
+			"responses": object{
+				"200": object{"description": "the table's columns", "content": jsonContent(ref("TableSchema"))},
+				"404": errorResponse,
+			},
+		}},
+		"/api/tables/{name}/export": object{"get": object{
+			"operationId": "exportTable",
+			"summary":     "Stream the active records of a table",
+			"parameters": []object{
+				{
+					"name": "name", "in": "path", "required": true,
+					"schema": object{"type": "string", "enum": names},
+				},
+				{
+					"name": "format", "in": "query",
+					"schema": object{"type": "string", "enum": []string{ExportNDJSON, ExportCSV, ExportParquet}, "default": ExportNDJSON},
+				},
+				fieldsParameter(nil),
+				whereParameter,
+			},
+			"responses": object{
+				"200": object{
+					"description": "the table, one row per line for ndjson and csv",
+					"content": object{
+						"application/x-ndjson":           object{"schema": object{"type": "string"}},
+						"text/csv":                       object{"schema": object{"type": "string"}},
+						"application/vnd.apache.parquet": object{"schema": object{"type": "string", "format": "binary"}},
+					},
+				},
+				"400": errorResponse,
+				"404": errorResponse,
+			},
+		}},
+	}
+
+	for _, db := range dbs {
+		table, err := adt.TableFromPath(filepath.Join(s.path, db))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", db, err)
+		}
+		name := tableName(db)
+		id := identifier(name)
+		schemas[id+"Row"] = s.rowSchema(db, table)
+		schemas[id+"Rows"] = object{
+			"type": "object",
+			"properties": object{
+				"table":  object{"type": "string"},
+				"total":  object{"type": "integer", "description": "number of rows matching the query"},
+				"offset": object{"type": "integer"},
+				"limit":  object{"type": "integer"},
+				"rows":   object{"type": "array", "items": ref(id + "Row")},
+				"next":   object{"type": "string", "description": "URL of the next page, absent on the last"},
+			},
+			"required": []string{"table", "total", "offset", "limit", "rows"},
+		}
+		paths["/api/tables/"+name+"/rows"] = object{"get": object{
+			"operationId": "list" + id + "Rows",
+			"summary":     "Page through the active records of " + name,
+			"parameters":  rowsParameters(table),
+			"responses": object{
+				"200": object{"description": "a page of rows", "content": jsonContent(ref(id + "Rows"))},
+				"400": errorResponse,
+			},
+		}}
+		table.Close()
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "ADT tables",
+			"version": "1.0",
+		},
+		"paths":      paths,
+		"components": object{"schemas": schemas},
+	}, nil
+}
+
+// rowSchema describes a row of table, read from the file db, as written by
+// the rows endpoint. Columns named in the foreign key Config say which
+// table they reference.
+func (s *Server) rowSchema(db string, table *adt.Table) object {
+	properties := object{}
+	var required []string
+	for _, c := range table.Columns {
+		p := columnSchema(c)
+		if c.Nullable() || s.Policy != nil {
+			p["nullable"] = true
+		} else {
+			required = append(required, c.Name)
+		}
+		if related := s.cfg[Table(db)][Column(c.Name)]; related != "" {
+			p["description"] = fmt.Sprintf("%s; references %s", p["description"], tableName(string(related)))
+			p["x-references"] = tableName(string(related))
+		}
+		properties[c.Name] = p
+	}
+	schema := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// columnSchema returns the JSON schema of values export.JSONWriter writes
+// for c.
+func columnSchema(c *adt.Column) object {
+	p := object{"description": strings.TrimPrefix(c.Type.String(), "ColumnType")}
+	switch c.Type {
+	case adt.ColumnTypeCharacter, adt.ColumnTypeCiCharacter:
+		p["type"], p["maxLength"] = "string", c.Length
+	case adt.ColumnTypeMemo:
+		p["type"] = "string"
+	case adt.ColumnTypeBlob:
+		p["type"], p["format"] = "string", "byte"
+	case adt.ColumnTypeBool:
+		p["type"] = "boolean"
+	case adt.ColumnTypeDouble:
+		p["type"], p["format"] = "number", "double"
+	case adt.ColumnTypeCurrency:
+		p["type"], p["format"] = "string", "decimal"
+		p["pattern"] = `^-?\d+\.\d+$`
+	case adt.ColumnTypeShortInt:
+		p["type"], p["format"] = "integer", "int32"
+		p["minimum"], p["maximum"] = -32768, 32767
+	case adt.ColumnTypeInt:
+		p["type"], p["format"] = "integer", "int32"
+	case adt.ColumnTypeAutoIncrement:
+		p["type"], p["format"], p["minimum"] = "integer", "int64", 0
+	case adt.ColumnTypeRowVersion:
+		p["type"], p["format"], p["minimum"] = "integer", "int64", 0
+	case adt.ColumnTypeDate:
+		p["type"], p["format"] = "string", "date"
+	case adt.ColumnTypeTimestamp, adt.ColumnTypeModTime:
+		// Values carry no zone, so they aren't RFC 3339 date-times.
+		p["type"] = "string"
+		p["pattern"] = `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}$`
+	case adt.ColumnTypeTime:
+		p["type"] = "string"
+		p["pattern"] = `^\d{2}:\d{2}:\d{2}\.\d{3}$`
+	}
+	return p
+}
+
+func rowsParameters(table *adt.Table) []object {
+	return []object{
+		queryParameter("offset", "index of the first matching row", object{"type": "integer", "minimum": 0, "default": 0}),
+		queryParameter("limit", "number of rows per page", object{"type": "integer", "minimum": 0, "maximum": MaxPageSize, "default": DefaultPageSize}),
+		fieldsParameter(table),
+		whereParameter,
+		queryParameter("order", "comma separated columns to sort by, prefixed with - for descending", object{"type": "string"}),
+	}
+}
+
+var whereParameter = object{
+	"name": "where", "in": "query", "explode": true,
+	"description": "condition COLUMN op VALUE, op one of = != < <= > >= ~ (contains); repeat to require several",
+	"schema":      object{"type": "array", "items": object{"type": "string"}},
+}
+
+// fieldsParameter describes the fields parameter, listing the columns of
+// table if it isn't nil.
+func fieldsParameter(table *adt.Table) object {
+	description := "comma separated columns to return"
+	if table != nil {
+		columns := make([]string, len(table.Columns))
+		for i, c := range table.Columns {
+			columns[i] = c.Name
+		}
+		description += ": " + strings.Join(columns, ", ")
+	}
+	return queryParameter("fields", description, object{"type": "string"})
+}
+
+func queryParameter(name, description string, schema object) object {
+	return object{"name": name, "in": "query", "description": description, "schema": schema}
+}
+
+func jsonContent(schema object) object {
+	return object{"application/json": object{"schema": schema}}
+}
+
+func ref(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+// identifier turns a table name into an exported Go-style identifier for
+// schema names, operation IDs and GraphQL type names: "ORDER_LINES" becomes
+// "OrderLines". Only ASCII letters and digits are kept, as GraphQL names
+// allow no others.
+func identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		upper = false
+	}
+	if b.Len() == 0 || unicode.IsDigit(rune(b.String()[0])) {
+		return "T" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"github.com/GeertJohan/go.rice/embedded"
+	"time"
+)
+
+func init() {
+
+	// define files
+	file2 := &embedded.EmbeddedFile{
+		Filename:    `base.tmpl`,
+		FileModTime: time.Unix(1792425225, 0),
+		Content:     string([]byte{0x3c, 0x21, 0x64, 0x6f, 0x63, 0x74, 0x79, 0x70, 0x65, 0x20, 0x68, 0x74, 0x6d, 0x6c, 0x3e, 0xa, 0x3c, 0x68, 0x74, 0x6d, 0x6c, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x68, 0x65, 0x61, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x3e, 0x7b, 0x7b, 0x20, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x20, 0x22, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x20, 0x2e, 0x20, 0x7d, 0x7d, 0x61, 0x64, 0x74, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x6d, 0x65, 0x74, 0x61, 0x20, 0x63, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74, 0x3d, 0x22, 0x75, 0x74, 0x66, 0x2d, 0x38, 0x22, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x6d, 0x65, 0x74, 0x61, 0x20, 0x6e, 0x61, 0x6d, 0x65, 0x3d, 0x22, 0x76, 0x69, 0x65, 0x77, 0x70, 0x6f, 0x72, 0x74, 0x22, 0x20, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x3d, 0x22, 0x77, 0x69, 0x64, 0x74, 0x68, 0x3d, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2d, 0x77, 0x69, 0x64, 0x74, 0x68, 0x2c, 0x20, 0x75, 0x73, 0x65, 0x72, 0x2d, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x3d, 0x6e, 0x6f, 0x22, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x6c, 0x69, 0x6e, 0x6b, 0x20, 0x72, 0x65, 0x6c, 0x3d, 0x22, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x73, 0x68, 0x65, 0x65, 0x74, 0x22, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x2f, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x2e, 0x63, 0x73, 0x73, 0x22, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x2f, 0x68, 0x65, 0x61, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x62, 0x6f, 0x64, 0x79, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x6e, 0x61, 0x76, 0x3e, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x2f, 0x22, 0x3e, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x20, 0x22, 0x6e, 0x61, 0x76, 0x22, 0x20, 0x2e, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x6e, 0x61, 0x76, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x20, 0x22, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x20, 0x2e, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x2f, 0x62, 0x6f, 0x64, 0x79, 0x3e, 0xa, 0x3c, 0x2f, 0x68, 0x74, 0x6d, 0x6c, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x63, 0x65, 0x6c, 0x6c, 0x22, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x4d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x73, 0x70, 0x61, 0x6e, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x6e, 0x75, 0x6c, 0x6c, 0x22, 0x3e, 0x6d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x3c, 0x2f, 0x73, 0x70, 0x61, 0x6e, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6c, 0x73, 0x65, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x4e, 0x75, 0x6c, 0x6c, 0x20, 0x7d, 0x7d, 0x3c, 0x73, 0x70, 0x61, 0x6e, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x6e, 0x75, 0x6c, 0x6c, 0x22, 0x3e, 0x4e, 0x55, 0x4c, 0x4c, 0x3c, 0x2f, 0x73, 0x70, 0x61, 0x6e, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6c, 0x73, 0x65, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x20, 0x7d, 0x7d, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x54, 0x65, 0x78, 0x74, 0x7d, 0x7d, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6c, 0x73, 0x65, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x2e, 0x54, 0x65, 0x78, 0x74, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x43, 0x6c, 0x69, 0x70, 0x70, 0x65, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x73, 0x70, 0x61, 0x6e, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x63, 0x6c, 0x69, 0x70, 0x70, 0x65, 0x64, 0x22, 0x3e, 0xe2, 0x80, 0xa6, 0x3c, 0x2f, 0x73, 0x70, 0x61, 0x6e, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa}), //++ TODO: optimize? (double allocation) or does compiler already optimize this?
+	}
+	file3 := &embedded.EmbeddedFile{
+		Filename:    `db.tmpl`,
+		FileModTime: time.Unix(1792425317, 0),
+		Content:     string([]byte{0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x6e, 0x61, 0x76, 0x22, 0x20, 0x7d, 0x7d, 0x20, 0x2f, 0x20, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x68, 0x31, 0x3e, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x3c, 0x2f, 0x68, 0x31, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x20, 0x7d, 0x7d, 0x3c, 0x70, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x3c, 0x2f, 0x70, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x6e, 0x6f, 0x74, 0x20, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x20, 0x7d, 0x7d, 0x3c, 0x70, 0x3e, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x52, 0x6f, 0x77, 0x73, 0x20, 0x7d, 0x7d, 0x72, 0x6f, 0x77, 0x73, 0x20, 0x7b, 0x7b, 0x2e, 0x46, 0x72, 0x6f, 0x6d, 0x7d, 0x7d, 0xe2, 0x80, 0x93, 0x7b, 0x7b, 0x2e, 0x54, 0x6f, 0x7d, 0x7d, 0x20, 0x6f, 0x66, 0x20, 0x7b, 0x7b, 0x2e, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6c, 0x73, 0x65, 0x20, 0x7d, 0x7d, 0x6e, 0x6f, 0x20, 0x72, 0x6f, 0x77, 0x73, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x65, 0x64, 0x20, 0x7d, 0x7d, 0x20, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x20, 0x74, 0x68, 0x65, 0x20, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x70, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x66, 0x6f, 0x72, 0x6d, 0x20, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x3d, 0x22, 0x67, 0x65, 0x74, 0x22, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x20, 0x7d, 0x7d, 0x3c, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x20, 0x74, 0x79, 0x70, 0x65, 0x3d, 0x22, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x22, 0x20, 0x6e, 0x61, 0x6d, 0x65, 0x3d, 0x22, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x22, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x3e, 0xa, 0x20, 0x20, 0x3c, 0x74, 0x68, 0x65, 0x61, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x68, 0x3e, 0x23, 0x3c, 0x2f, 0x74, 0x68, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x20, 0x7d, 0x7d, 0x3c, 0x74, 0x68, 0x3e, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x20, 0x7d, 0x7d, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6c, 0x73, 0x65, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x65, 0x71, 0x20, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x20, 0x22, 0x61, 0x73, 0x63, 0x22, 0x20, 0x7d, 0x7d, 0x20, 0xe2, 0x96, 0xb2, 0x7b, 0x7b, 0x20, 0x65, 0x6c, 0x73, 0x65, 0x20, 0x69, 0x66, 0x20, 0x65, 0x71, 0x20, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x20, 0x22, 0x64, 0x65, 0x73, 0x63, 0x22, 0x20, 0x7d, 0x7d, 0x20, 0xe2, 0x96, 0xbc, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x62, 0x72, 0x3e, 0x3c, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x3e, 0x7b, 0x7b, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x7d, 0x7d, 0x3c, 0x2f, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x3e, 0x3c, 0x2f, 0x74, 0x68, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x2f, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x72, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x22, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x68, 0x3e, 0x3c, 0x62, 0x75, 0x74, 0x74, 0x6f, 0x6e, 0x20, 0x74, 0x79, 0x70, 0x65, 0x3d, 0x22, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x22, 0x3e, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x3c, 0x2f, 0x62, 0x75, 0x74, 0x74, 0x6f, 0x6e, 0x3e, 0x3c, 0x2f, 0x74, 0x68, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x20, 0x7d, 0x7d, 0x3c, 0x74, 0x68, 0x3e, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x6e, 0x6f, 0x74, 0x20, 0x2e, 0x4d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x20, 0x6e, 0x61, 0x6d, 0x65, 0x3d, 0x22, 0x77, 0x2e, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x22, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x7d, 0x7d, 0x22, 0x20, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x3d, 0x22, 0x74, 0x65, 0x78, 0x74, 0x20, 0x74, 0x6f, 0x20, 0x6c, 0x6f, 0x6f, 0x6b, 0x20, 0x66, 0x6f, 0x72, 0x2c, 0x20, 0x6f, 0x72, 0x20, 0x3d, 0x2c, 0x20, 0x21, 0x3d, 0x2c, 0x20, 0x26, 0x6c, 0x74, 0x3b, 0x2c, 0x20, 0x26, 0x6c, 0x74, 0x3b, 0x3d, 0x2c, 0x20, 0x26, 0x67, 0x74, 0x3b, 0x2c, 0x20, 0x26, 0x67, 0x74, 0x3b, 0x3d, 0x20, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x20, 0x62, 0x79, 0x20, 0x61, 0x20, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x74, 0x68, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x2f, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x3c, 0x2f, 0x74, 0x68, 0x65, 0x61, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x3c, 0x74, 0x62, 0x6f, 0x64, 0x79, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x52, 0x6f, 0x77, 0x73, 0x20, 0x7d, 0x7d, 0x3c, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x64, 0x3e, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x7d, 0x7d, 0x3c, 0x2f, 0x61, 0x3e, 0x3c, 0x2f, 0x74, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x20, 0x7d, 0x7d, 0x3c, 0x74, 0x64, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x20, 0x7d, 0x7d, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x6d, 0x65, 0x6d, 0x6f, 0x22, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3e, 0x7b, 0x7b, 0x20, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x20, 0x22, 0x63, 0x65, 0x6c, 0x6c, 0x22, 0x20, 0x2e, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x74, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x2f, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x20, 0x20, 0x3c, 0x2f, 0x74, 0x62, 0x6f, 0x64, 0x79, 0x3e, 0xa, 0x3c, 0x2f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x3e, 0xa, 0x3c, 0x2f, 0x66, 0x6f, 0x72, 0x6d, 0x3e, 0xa, 0x3c, 0x70, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x70, 0x61, 0x67, 0x65, 0x73, 0x22, 0x3e, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x50, 0x72, 0x65, 0x76, 0x20, 0x7d, 0x7d, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x22, 0x3e, 0xe2, 0x86, 0x90, 0x20, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x20, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x20, 0x7d, 0x7d, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x22, 0x3e, 0x6e, 0x65, 0x78, 0x74, 0x20, 0xe2, 0x86, 0x92, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x70, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa}), //++ TODO: optimize? (double allocation) or does compiler already optimize this?
+	}
+	file4 := &embedded.EmbeddedFile{
+		Filename:    `index.tmpl`,
+		FileModTime: time.Unix(1455945138, 0),
+		Content:     string([]byte{0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x20, 0x7d, 0x7d, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x20, 0x7d, 0x7d, 0xa, 0x64, 0x62, 0x73, 0x3a, 0x20, 0x7b, 0x7b, 0x20, 0x2e, 0x20, 0x7c, 0x20, 0x6c, 0x65, 0x6e, 0x20, 0x7d, 0x7d, 0xa, 0xa, 0x3c, 0x75, 0x6c, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x20, 0x7d, 0x7d, 0xa, 0x20, 0x20, 0x3c, 0x6c, 0x69, 0x3e, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x2f, 0x64, 0x62, 0x73, 0x2f, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x3c, 0x2f, 0x61, 0x3e, 0x3c, 0x2f, 0x6c, 0x69, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x2f, 0x75, 0x6c, 0x3e, 0xa, 0xa, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa}), //++ TODO: optimize? (double allocation) or does compiler already optimize this?
+	}
+	file5 := &embedded.EmbeddedFile{
+		Filename:    `record.tmpl`,
+		FileModTime: time.Unix(1792425225, 0),
+		Content:     string([]byte{0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x20, 0x7d, 0x7d, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x20, 0x7b, 0x7b, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x6e, 0x61, 0x76, 0x22, 0x20, 0x7d, 0x7d, 0x20, 0x2f, 0x20, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x3c, 0x2f, 0x61, 0x3e, 0x20, 0x2f, 0x20, 0x7b, 0x7b, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x7b, 0x7b, 0x20, 0x64, 0x65, 0x66, 0x69, 0x6e, 0x65, 0x20, 0x22, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x68, 0x31, 0x3e, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x20, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x20, 0x7b, 0x7b, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x7d, 0x7d, 0x20, 0x6f, 0x66, 0x20, 0x7b, 0x7b, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x7d, 0x7d, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x20, 0x7d, 0x7d, 0x20, 0x3c, 0x73, 0x70, 0x61, 0x6e, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x3e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x3c, 0x2f, 0x73, 0x70, 0x61, 0x6e, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x68, 0x31, 0x3e, 0xa, 0x3c, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x22, 0x3e, 0xa, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x20, 0x7d, 0x7d, 0x3c, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x68, 0x3e, 0x7b, 0x7b, 0x2e, 0x4e, 0x61, 0x6d, 0x65, 0x7d, 0x7d, 0x3c, 0x62, 0x72, 0x3e, 0x3c, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x3e, 0x7b, 0x7b, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x7d, 0x7d, 0x3c, 0x2f, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x3e, 0x3c, 0x2f, 0x74, 0x68, 0x3e, 0xa, 0x20, 0x20, 0x20, 0x20, 0x3c, 0x74, 0x64, 0x7b, 0x7b, 0x20, 0x69, 0x66, 0x20, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x20, 0x7d, 0x7d, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x6d, 0x65, 0x6d, 0x6f, 0x22, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3e, 0x7b, 0x7b, 0x20, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x20, 0x22, 0x63, 0x65, 0x6c, 0x6c, 0x22, 0x20, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x74, 0x64, 0x3e, 0xa, 0x20, 0x20, 0x3c, 0x2f, 0x74, 0x72, 0x3e, 0xa, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x2f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x52, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x73, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x68, 0x32, 0x3e, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x20, 0x62, 0x79, 0x3c, 0x2f, 0x68, 0x32, 0x3e, 0xa, 0x3c, 0x75, 0x6c, 0x3e, 0xa, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x20, 0x2e, 0x20, 0x7d, 0x7d, 0x3c, 0x6c, 0x69, 0x3e, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x4c, 0x69, 0x6e, 0x6b, 0x7d, 0x7d, 0x22, 0x3e, 0x7b, 0x7b, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x7d, 0x7d, 0x20, 0x62, 0x79, 0x20, 0x7b, 0x7b, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x7d, 0x7d, 0x3c, 0x2f, 0x61, 0x3e, 0x3c, 0x2f, 0x6c, 0x69, 0x3e, 0xa, 0x20, 0x20, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x2f, 0x75, 0x6c, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa, 0x3c, 0x70, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x3d, 0x22, 0x70, 0x61, 0x67, 0x65, 0x73, 0x22, 0x3e, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x50, 0x72, 0x65, 0x76, 0x20, 0x7d, 0x7d, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x22, 0x3e, 0xe2, 0x86, 0x90, 0x20, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x20, 0x7b, 0x7b, 0x20, 0x77, 0x69, 0x74, 0x68, 0x20, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x20, 0x7d, 0x7d, 0x3c, 0x61, 0x20, 0x68, 0x72, 0x65, 0x66, 0x3d, 0x22, 0x7b, 0x7b, 0x2e, 0x7d, 0x7d, 0x22, 0x3e, 0x6e, 0x65, 0x78, 0x74, 0x20, 0xe2, 0x86, 0x92, 0x3c, 0x2f, 0x61, 0x3e, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0x3c, 0x2f, 0x70, 0x3e, 0xa, 0x7b, 0x7b, 0x20, 0x65, 0x6e, 0x64, 0x20, 0x7d, 0x7d, 0xa}), //++ TODO: optimize? (double allocation) or does compiler already optimize this?
+	}
+	file6 := &embedded.EmbeddedFile{
+		Filename:    `style.css`,
+		FileModTime: time.Unix(1792425225, 0),
+		Content:     string([]byte{0x62, 0x6f, 0x64, 0x79, 0x20, 0x7b, 0x20, 0x66, 0x6f, 0x6e, 0x74, 0x3a, 0x20, 0x31, 0x34, 0x70, 0x78, 0x2f, 0x31, 0x2e, 0x34, 0x20, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x2d, 0x75, 0x69, 0x2c, 0x20, 0x73, 0x61, 0x6e, 0x73, 0x2d, 0x73, 0x65, 0x72, 0x69, 0x66, 0x3b, 0x20, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x3a, 0x20, 0x31, 0x65, 0x6d, 0x20, 0x32, 0x65, 0x6d, 0x3b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x32, 0x32, 0x32, 0x3b, 0x20, 0x7d, 0xa, 0x6e, 0x61, 0x76, 0x20, 0x7b, 0x20, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x2d, 0x62, 0x6f, 0x74, 0x74, 0x6f, 0x6d, 0x3a, 0x20, 0x31, 0x65, 0x6d, 0x3b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x36, 0x36, 0x36, 0x3b, 0x20, 0x7d, 0xa, 0x61, 0x20, 0x7b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x30, 0x35, 0x35, 0x30, 0x61, 0x65, 0x3b, 0x20, 0x74, 0x65, 0x78, 0x74, 0x2d, 0x64, 0x65, 0x63, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x3a, 0x20, 0x6e, 0x6f, 0x6e, 0x65, 0x3b, 0x20, 0x7d, 0xa, 0x61, 0x3a, 0x68, 0x6f, 0x76, 0x65, 0x72, 0x20, 0x7b, 0x20, 0x74, 0x65, 0x78, 0x74, 0x2d, 0x64, 0x65, 0x63, 0x6f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x3a, 0x20, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x6c, 0x69, 0x6e, 0x65, 0x3b, 0x20, 0x7d, 0xa, 0x68, 0x31, 0x20, 0x7b, 0x20, 0x66, 0x6f, 0x6e, 0x74, 0x2d, 0x73, 0x69, 0x7a, 0x65, 0x3a, 0x20, 0x31, 0x2e, 0x34, 0x65, 0x6d, 0x3b, 0x20, 0x7d, 0xa, 0x68, 0x32, 0x20, 0x7b, 0x20, 0x66, 0x6f, 0x6e, 0x74, 0x2d, 0x73, 0x69, 0x7a, 0x65, 0x3a, 0x20, 0x31, 0x2e, 0x31, 0x65, 0x6d, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x20, 0x7b, 0x20, 0x62, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x2d, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x3a, 0x20, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x68, 0x2c, 0x20, 0x74, 0x64, 0x20, 0x7b, 0x20, 0x62, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x3a, 0x20, 0x31, 0x70, 0x78, 0x20, 0x73, 0x6f, 0x6c, 0x69, 0x64, 0x20, 0x23, 0x64, 0x64, 0x64, 0x3b, 0x20, 0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x3a, 0x20, 0x30, 0x2e, 0x32, 0x35, 0x65, 0x6d, 0x20, 0x30, 0x2e, 0x35, 0x65, 0x6d, 0x3b, 0x20, 0x74, 0x65, 0x78, 0x74, 0x2d, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x3a, 0x20, 0x6c, 0x65, 0x66, 0x74, 0x3b, 0x20, 0x76, 0x65, 0x72, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x2d, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x3a, 0x20, 0x74, 0x6f, 0x70, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x68, 0x65, 0x61, 0x64, 0x20, 0x74, 0x68, 0x20, 0x7b, 0x20, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x3a, 0x20, 0x23, 0x66, 0x34, 0x66, 0x34, 0x66, 0x34, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x68, 0x20, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x20, 0x7b, 0x20, 0x66, 0x6f, 0x6e, 0x74, 0x2d, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x3a, 0x20, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x3b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x36, 0x36, 0x36, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x72, 0x2e, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x20, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x20, 0x7b, 0x20, 0x77, 0x69, 0x64, 0x74, 0x68, 0x3a, 0x20, 0x31, 0x30, 0x30, 0x25, 0x3b, 0x20, 0x6d, 0x69, 0x6e, 0x2d, 0x77, 0x69, 0x64, 0x74, 0x68, 0x3a, 0x20, 0x34, 0x65, 0x6d, 0x3b, 0x20, 0x62, 0x6f, 0x78, 0x2d, 0x73, 0x69, 0x7a, 0x69, 0x6e, 0x67, 0x3a, 0x20, 0x62, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x2d, 0x62, 0x6f, 0x78, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x62, 0x6f, 0x64, 0x79, 0x20, 0x74, 0x72, 0x3a, 0x6e, 0x74, 0x68, 0x2d, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x28, 0x65, 0x76, 0x65, 0x6e, 0x29, 0x20, 0x7b, 0x20, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x3a, 0x20, 0x23, 0x66, 0x61, 0x66, 0x61, 0x66, 0x61, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x64, 0x2e, 0x6d, 0x65, 0x6d, 0x6f, 0x20, 0x7b, 0x20, 0x77, 0x68, 0x69, 0x74, 0x65, 0x2d, 0x73, 0x70, 0x61, 0x63, 0x65, 0x3a, 0x20, 0x70, 0x72, 0x65, 0x2d, 0x77, 0x72, 0x61, 0x70, 0x3b, 0x20, 0x6d, 0x61, 0x78, 0x2d, 0x77, 0x69, 0x64, 0x74, 0x68, 0x3a, 0x20, 0x34, 0x30, 0x65, 0x6d, 0x3b, 0x20, 0x7d, 0xa, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x20, 0x74, 0x68, 0x20, 0x7b, 0x20, 0x62, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x3a, 0x20, 0x23, 0x66, 0x34, 0x66, 0x34, 0x66, 0x34, 0x3b, 0x20, 0x7d, 0xa, 0x2e, 0x6e, 0x75, 0x6c, 0x6c, 0x20, 0x7b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x39, 0x39, 0x39, 0x3b, 0x20, 0x66, 0x6f, 0x6e, 0x74, 0x2d, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x3a, 0x20, 0x69, 0x74, 0x61, 0x6c, 0x69, 0x63, 0x3b, 0x20, 0x7d, 0xa, 0x2e, 0x63, 0x6c, 0x69, 0x70, 0x70, 0x65, 0x64, 0x20, 0x7b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x39, 0x39, 0x39, 0x3b, 0x20, 0x7d, 0xa, 0x2e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x20, 0x7b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x62, 0x30, 0x30, 0x3b, 0x20, 0x66, 0x6f, 0x6e, 0x74, 0x2d, 0x73, 0x69, 0x7a, 0x65, 0x3a, 0x20, 0x30, 0x2e, 0x37, 0x65, 0x6d, 0x3b, 0x20, 0x76, 0x65, 0x72, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x2d, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x3a, 0x20, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x3b, 0x20, 0x7d, 0xa, 0x2e, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x20, 0x7b, 0x20, 0x63, 0x6f, 0x6c, 0x6f, 0x72, 0x3a, 0x20, 0x23, 0x62, 0x30, 0x30, 0x3b, 0x20, 0x7d, 0xa, 0x2e, 0x70, 0x61, 0x67, 0x65, 0x73, 0x20, 0x61, 0x20, 0x7b, 0x20, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x2d, 0x72, 0x69, 0x67, 0x68, 0x74, 0x3a, 0x20, 0x31, 0x65, 0x6d, 0x3b, 0x20, 0x7d, 0xa}), //++ TODO: optimize? (double allocation) or does compiler already optimize this?
+	}
+
+	// define dirs
+	dir1 := &embedded.EmbeddedDir{
+		Filename:   ``,
+		DirModTime: time.Unix(1792425317, 0),
+		ChildFiles: []*embedded.EmbeddedFile{
+			file2, // base.tmpl
+			file3, // db.tmpl
+			file4, // index.tmpl
+			file5, // record.tmpl
+			file6, // style.css
+
+		},
+	}
+
+	// link ChildDirs
+	dir1.ChildDirs = []*embedded.EmbeddedDir{}
+
+	// register embeddedBox
+	embedded.RegisterEmbeddedBox(`templates`, &embedded.EmbeddedBox{
+		Name: `templates`,
+		Time: time.Unix(1792425317, 0),
+		Dirs: map[string]*embedded.EmbeddedDir{
+			"": dir1,
+		},
+		Files: map[string]*embedded.EmbeddedFile{
+			"base.tmpl":   file2,
+			"db.tmpl":     file3,
+			"index.tmpl":  file4,
+			"record.tmpl": file5,
+			"style.css":   file6,
+		},
+	})
+}
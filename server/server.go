@@ -0,0 +1,341 @@
+// Package server serves ADT tables over HTTP.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/GeertJohan/go.rice"
+	"github.com/tmc/adt"
+)
+
+var startTime = time.Now()
+
+// Server serves the ADT files in a directory.
+type Server struct {
+	// Auth identifies the principal of each request; the first
+	// Authenticator recognizing the request's credentials decides. If it
+	// is empty requests are anonymous, otherwise requests none of them
+	// recognize get a 401.
+	Auth []Authenticator
+	// Policy limits the tables and columns each principal may read; nil
+	// allows everything.
+	Policy Policy
+	// ClientCAs verifies TLS client certificates for the ClientCert
+	// Authenticator. Certificates are requested but not required.
+	ClientCAs *x509.CertPool
+	// Origins lists the origins whose browser pages may read responses,
+	// "*" standing for any. If it is empty no CORS headers are sent.
+	Origins []string
+	// Limits bounds the resources requests may use.
+	Limits Limits
+	// AccessLog, if set, gets an entry for each request served.
+	AccessLog *slog.Logger
+
+	cfg     Config
+	path    string
+	verbose bool
+	metrics *metrics
+
+	schemaMu   sync.Mutex
+	schemasKey string
+	schemas    map[string]*graphQLSchema
+}
+
+// New returns a Server for the ADT files in path. cfg, which may be nil,
+// names the foreign keys used to expand related records.
+func New(cfg Config, path string, verbose bool) *Server {
+	return &Server{cfg: cfg, path: path, verbose: verbose, Limits: DefaultLimits, metrics: newMetrics()}
+}
+
+// Handler returns the server's HTTP handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.srvIndex)
+	mux.HandleFunc("/dbs/", s.srvDBs)
+	mux.HandleFunc("/api/", s.srvAPI)
+	mux.HandleFunc("/openapi.json", s.srvOpenAPI)
+	mux.HandleFunc("/graphql", s.srvGraphQL)
+	mux.HandleFunc("/metrics", s.srvMetrics)
+	mux.HandleFunc("/style.css", s.srvStyle)
+	auth := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r = s.authenticate(rw, r); r != nil {
+			mux.ServeHTTP(rw, r)
+		}
+	})
+	return s.instrument(securityHeaders(s.cors(s.Limits.limit(auth))))
+}
+
+// Serve listens on addr, using TLS when both key paths are given, until
+// the process is interrupted or terminated. It then shuts down gracefully.
+func (s *Server) Serve(addr string, publicKeyPath string, privateKeyPath string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.ServeContext(ctx, addr, publicKeyPath, privateKeyPath)
+}
+
+// ServeContext is like Serve but shuts down when ctx is done, waiting up
+// to the ShutdownTimeout for requests in flight before closing their
+// connections. Client certificates need TLS, so it fails at once if
+// ClientCAs is set without both key paths.
+func (s *Server) ServeContext(ctx context.Context, addr string, publicKeyPath string, privateKeyPath string) error {
+	if s.ClientCAs != nil && (publicKeyPath == "" || privateKeyPath == "") {
+		return errors.New("client certificates need TLS: a certificate and key are required")
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: s.Limits.ReadTimeout,
+		ReadTimeout:       s.Limits.ReadTimeout,
+		WriteTimeout:      s.Limits.WriteTimeout,
+		IdleTimeout:       s.Limits.IdleTimeout,
+	}
+	errc := make(chan error, 1)
+	go func() {
+		if publicKeyPath != "" && privateKeyPath != "" {
+			if s.ClientCAs != nil {
+				srv.TLSConfig = &tls.Config{ClientCAs: s.ClientCAs, ClientAuth: tls.VerifyClientCertIfGiven}
+			}
+			errc <- srv.ListenAndServeTLS(publicKeyPath, privateKeyPath)
+			return
+		}
+		errc <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	log.Println("shutting down")
+	sctx := context.Background()
+	if s.Limits.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		sctx, cancel = context.WithTimeout(sctx, s.Limits.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := srv.Shutdown(sctx); err != nil {
+		srv.Close()
+		return err
+	}
+	return nil
+}
+
+func (s *Server) srvIndex(rw http.ResponseWriter, r *http.Request) {
+	dbs, err := s.visible(r.Context())
+	if renderErr(rw, err) {
+		return
+	}
+	render(rw, "index.tmpl", dbs)
+}
+
+func (s *Server) srvDBs(rw http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path[len("/dbs/"):]
+	parts := strings.Split(name, "/")
+	switch len(parts) {
+	case 1:
+		s.srvDBIndex(rw, r)
+	case 2:
+		s.srvDBRecord(rw, r)
+	}
+}
+
+func (s *Server) srvDBIndex(rw http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path[len("/dbs/"):]
+	parts := strings.Split(name, "/")
+	table, a, ok := s.apiTable(rw, r, parts[0])
+	if !ok {
+		return
+	}
+	defer table.Close()
+	if query := r.URL.Query().Get("q"); query != "" {
+		rw.Header().Add("Content-Type", "application/json")
+		field := r.URL.Query().Get("field")
+		scanned := 0
+		defer func() { s.metrics.scanned(table.Name, scanned) }()
+		for i := int(table.RecordCount) - 1; i >= 0; i-- {
+			data, err := table.Get(i)
+			if renderErr(rw, err) {
+				return
+			}
+			scanned++
+			a.mask(table, data)
+			if fmt.Sprint(data[field]) == query {
+				data, err = s.decorateRecord(r.Context(), table.Name, data)
+				if renderErr(rw, err) {
+					return
+				}
+				json.NewEncoder(rw).Encode(data)
+				return
+			}
+		}
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.browseTable(rw, r, table, a)
+}
+
+func (s *Server) srvDBRecord(rw http.ResponseWriter, r *http.Request) {
+	name := r.URL.Path[len("/dbs/"):]
+	parts := strings.Split(name, "/")
+	table, a, ok := s.apiTable(rw, r, parts[0])
+	if !ok {
+		return
+	}
+	defer table.Close()
+	index, err := strconv.Atoi(parts[1])
+	if renderErr(rw, err) {
+		return
+	}
+	if index < 1 || index > int(table.RecordCount) {
+		http.NotFound(rw, r)
+		return
+	}
+	data, err := table.Get(index - 1)
+	if renderErr(rw, err) {
+		return
+	}
+	a.mask(table, data)
+	if wantsHTML(r) {
+		s.browseRecord(rw, r, table, a, index, data)
+		return
+	}
+	data, err = s.decorateRecord(r.Context(), table.Name, data)
+	if renderErr(rw, err) {
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(data)
+}
+
+func (s *Server) decorateRecord(ctx context.Context, table string, record map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	tblConf, hasConf := s.cfg[Table(table)]
+
+	for key, value := range record {
+		if hasConf && tblConf[Column(key)] != "" {
+			related, err := s.lookupRecord(ctx, tblConf[Column(key)], value)
+			if err != nil {
+				log.Println("issue looking up related record", table, key, value, err)
+			} else {
+				value = related
+			}
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// lookupRecord returns the record of tableName whose primary key is pk, as
+// the request's principal may see it, or errNoRecord if there is none.
+// Records found, and missing, are remembered for the rest of the request.
+func (s *Server) lookupRecord(ctx context.Context, tableName Table, pk interface{}) (map[string]interface{}, error) {
+	key := fmt.Sprint(pk)
+	if record, ok := info(ctx).lookup(tableName, key); ok {
+		s.metrics.lookup(true)
+		if record == nil {
+			return nil, errNoRecord
+		}
+		return record, nil
+	}
+	s.metrics.lookup(false)
+	record, err := s.findRecord(ctx, tableName, key)
+	if err == nil || err == errNoRecord {
+		info(ctx).store(tableName, key, record)
+	}
+	return record, err
+}
+
+// findRecord scans tableName for the record whose primary key prints as pk.
+// It returns adt.ErrNoPK if the table has no primary key and errNoRecord if
+// no record has pk.
+func (s *Server) findRecord(ctx context.Context, tableName Table, pk string) (map[string]interface{}, error) {
+	db, table, err := s.openTable(string(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer table.Close()
+	a, ok := s.access(ctx, db, table)
+	if !ok {
+		return nil, errForbidden
+	}
+	pkCol, err := table.GetPK()
+	if err != nil {
+		return nil, err
+	}
+	if pkCol == nil {
+		return nil, adt.ErrNoPK
+	}
+	scanned := 0
+	defer func() { s.metrics.scanned(table.Name, scanned) }()
+	for i := int(table.RecordCount) - 1; i >= 0; i-- {
+		record, err := table.Get(i)
+		if err != nil {
+			return nil, err
+		}
+		scanned++
+		if fmt.Sprint(record[pkCol.Name]) == pk {
+			a.mask(table, record)
+			return record, nil
+		}
+	}
+	return nil, errNoRecord
+}
+
+func render(rw http.ResponseWriter, tmpl string, data interface{}) {
+	rw.Header().Set("x-uptime", fmt.Sprint(time.Now().Sub(startTime)))
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	t, err := getTmpl(tmpl)
+	if renderErr(rw, err) {
+		return
+	}
+	t.Execute(rw, data)
+}
+
+func renderErr(rw http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+	rw.WriteHeader(500)
+	rw.Write([]byte(err.Error()))
+	return true
+}
+
+func getTmpl(name string) (*template.Template, error) {
+	templates := rice.MustFindBox("templates")
+	t, err := template.New("").Parse(templates.MustString("base.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := templates.String(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Parse(tmpl)
+}
+
+func (s *Server) listdbs() ([]string, error) {
+	result, err := filepath.Glob(filepath.Join(s.path, "*.ADT"))
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range result {
+		result[i] = filepath.Base(s)
+	}
+	return result, nil
+}
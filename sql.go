@@ -2,34 +2,317 @@ package adt
 
 import (
 	"bytes"
-	"fmt"
 	"strings"
 	"text/template"
 )
 
-var ddlTmpl = `CREATE TABLE IF NOT EXISTS ` + "`{{.TableName}}`" + ` (
+var ddlTmpl = `{{create .TableName}} (
 {{range $index, $column := .Columns }}{{if $index}},{{end}}
-	` + "`{{.Name}}`" + ` {{.Type.SQLType}}{{end}}
-)`
+	{{quote .Name}} {{columnType .}}{{if notNull .}} NOT NULL{{end}}{{end}}{{with .PrimaryKey}},
+	PRIMARY KEY ({{quoteList .}}){{end}}{{range .Unique}},
+	{{with .Name}}CONSTRAINT {{quote .}} {{end}}UNIQUE ({{quoteList .Columns}}){{end}}
+){{with options}} {{.}}{{end}}`
 
+// SQLDDL returns a MySQL CREATE TABLE statement for the table.
 func (t *Table) SQLDDL(tableName string) (string, error) {
-	tmpl, err := template.New("").Parse(ddlTmpl)
+	return t.DialectDDL(MySQL, tableName)
+}
+
+// DialectDDL returns a CREATE TABLE statement for the table in the given
+// dialect. Columns that can't hold NULL and primary key columns are declared
+// NOT NULL, and unique indexes are emitted as UNIQUE constraints.
+func (t *Table) DialectDDL(d Dialect, tableName string) (string, error) {
+	pk, err := t.primaryKey()
+	if err != nil {
+		return "", err
+	}
+	isPK := make(map[string]bool)
+	for _, name := range pk {
+		isPK[name] = true
+	}
+	quoteList := func(names []string) string {
+		quoted := make([]string, 0, len(names))
+		for _, name := range names {
+			quoted = append(quoted, d.QuoteIdent(name))
+		}
+		return strings.Join(quoted, ", ")
+	}
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"create":     func(name string) string { return d.CreateTable(d.QuoteIdent(name)) },
+		"quote":      d.QuoteIdent,
+		"quoteList":  quoteList,
+		"columnType": d.ColumnType,
+		"notNull":    func(c *Column) bool { return !c.Nullable() || isPK[c.Name] },
+		"options":    d.TableOptions,
+	}).Parse(ddlTmpl)
 	if err != nil {
 		return "", err
 	}
 	output := new(bytes.Buffer)
 	err = tmpl.Execute(output, map[string]interface{}{
-		"TableName": tableName,
-		"Columns":   t.Columns,
+		"TableName":  tableName,
+		"Columns":    t.Columns,
+		"PrimaryKey": pk,
+		"Unique":     t.uniqueIndexes(),
 	})
 	return string(output.Bytes()), err
 }
 
+// IndexDDL returns CREATE INDEX statements for the table's non-unique
+// indexes in the given dialect. Indexes without a name are named after the
+// table and their columns.
+func (t *Table) IndexDDL(d Dialect, tableName string) []string {
+	var result []string
+	for _, idx := range t.Indexes {
+		if idx.Primary || idx.Unique {
+			continue
+		}
+		name := idx.Name
+		if name == "" {
+			name = "idx_" + tableName + "_" + strings.Join(idx.Columns, "_")
+		}
+		columns := make([]string, 0, len(idx.Columns))
+		for _, c := range idx.Columns {
+			columns = append(columns, d.QuoteIdent(c))
+		}
+		result = append(result, d.CreateIndex(name, tableName)+" ("+strings.Join(columns, ", ")+")")
+	}
+	return result
+}
+
+// InsertSQL returns a MySQL INSERT statement with one placeholder per column.
 func (t *Table) InsertSQL(tableName string) string {
-	placeHolders := make([]string, 0, len(t.Columns))
-	for i := 0; i < len(t.Columns); i++ {
-		placeHolders = append(placeHolders, "?") // todo(mysqlism)
+	return t.DialectInsertSQL(MySQL, tableName)
+}
+
+// DialectInsertSQL returns an INSERT statement naming every column, with
+// one placeholder per column in the given dialect.
+func (t *Table) DialectInsertSQL(d Dialect, tableName string) string {
+	return t.BatchInsertSQL(d, tableName, 1)
+}
+
+// BatchInsertSQL returns a multi-row INSERT statement in the given dialect
+// with placeholders for rows records. Arguments are bound row by row in
+// column order.
+func (t *Table) BatchInsertSQL(d Dialect, tableName string, rows int) string {
+	values := make([]string, 0, rows)
+	n := 1
+	for row := 0; row < rows; row++ {
+		placeHolders := make([]string, 0, len(t.Columns))
+		for i := 0; i < len(t.Columns); i++ {
+			placeHolders = append(placeHolders, d.Placeholder(n))
+			n++
+		}
+		values = append(values, "("+strings.Join(placeHolders, ",")+")")
+	}
+	columns := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		columns = append(columns, d.QuoteIdent(c.Name))
+	}
+	return "INSERT INTO " + d.QuoteIdent(tableName) + " (" + strings.Join(columns, ",") + ") VALUES" + strings.Join(values, ",")
+}
+
+// BatchUpsertSQL returns a multi-row INSERT statement like BatchInsertSQL
+// that updates existing rows with the same primary key instead of failing.
+func (t *Table) BatchUpsertSQL(d Dialect, tableName string, rows int) (string, error) {
+	pk, err := t.primaryKey()
+	if err != nil {
+		return "", err
+	}
+	if len(pk) == 0 {
+		return "", ErrNoPK
+	}
+	isPK := make(map[string]bool)
+	for _, name := range pk {
+		isPK[name] = true
+	}
+	var columns []string
+	for _, c := range t.Columns {
+		if !isPK[c.Name] {
+			columns = append(columns, c.Name)
+		}
+	}
+	if len(columns) == 0 {
+		columns = pk
+	}
+	clause := d.UpsertClause(pk, columns)
+	if clause == "" {
+		return "", ErrUpsertUnsupported
+	}
+	return t.BatchInsertSQL(d, tableName, rows) + " " + clause, nil
+}
+
+// IdentityInsertSQL returns the statement that allows (on) or forbids
+// inserting the table's AutoIncrement values into tableName, or "" if the
+// table has no AutoIncrement column or the dialect needs no statement.
+// Loads that keep the ADT keys run it before and after their INSERTs, in
+// the same session.
+func (t *Table) IdentityInsertSQL(d Dialect, tableName string, on bool) string {
+	for _, c := range t.Columns {
+		if c.Type == ColumnTypeAutoIncrement {
+			return d.IdentityInsert(tableName, on)
+		}
+	}
+	return ""
+}
+
+// ResetSequenceSQL returns the statements that move the generators of the
+// AutoIncrement columns of tableName past the loaded keys, so that rows
+// inserted later get new ones.
+func (t *Table) ResetSequenceSQL(d Dialect, tableName string) []string {
+	var result []string
+	for _, c := range t.Columns {
+		if c.Type != ColumnTypeAutoIncrement {
+			continue
+		}
+		if stmt := d.ResetSequence(tableName, c.Name); stmt != "" {
+			result = append(result, stmt)
+		}
+	}
+	return result
+}
+
+// SQLColumn describes a column of an existing SQL table, as listed by
+// information_schema.columns.
+type SQLColumn struct {
+	Name string
+	// Type is the column's data type, such as "character varying" or
+	// "VARCHAR(40)"; any length or precision is ignored.
+	Type     string
+	Nullable bool
+}
+
+// ColumnChange pairs an ADT column with the SQL column of the same name
+// when their types or nullability differ.
+type ColumnChange struct {
+	Column   *Column
+	Existing SQLColumn
+	// Nullable is whether the generated DDL lets the column hold NULL.
+	Nullable bool
+}
+
+// SchemaDiff describes how the columns of an existing SQL table differ from
+// those of an ADT table.
+type SchemaDiff struct {
+	// Added lists the ADT columns missing from the SQL table.
+	Added []*Column
+	// Dropped lists the SQL columns with no corresponding ADT column.
+	Dropped []string
+	// Changed lists the columns whose SQL type or nullability doesn't
+	// match the DDL generated for the ADT column.
+	Changed []ColumnChange
+}
+
+// Empty reports whether the schemas match.
+func (diff *SchemaDiff) Empty() bool {
+	return len(diff.Added) == 0 && len(diff.Dropped) == 0 && len(diff.Changed) == 0
+}
+
+// Diff compares the table's columns with those of an existing SQL table in
+// the given dialect. Names match as the dialect matches quoted
+// identifiers: exactly in PostgreSQL, ignoring case elsewhere.
+func (t *Table) Diff(d Dialect, existing []SQLColumn) (*SchemaDiff, error) {
+	pk, err := t.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+	isPK := make(map[string]bool)
+	for _, name := range pk {
+		isPK[name] = true
+	}
+	key := func(name string) string {
+		if d.Name() == PostgreSQL.Name() {
+			return name
+		}
+		return strings.ToUpper(name)
+	}
+	diff := &SchemaDiff{}
+	have := make(map[string]SQLColumn)
+	for _, c := range existing {
+		have[key(c.Name)] = c
+	}
+	want := make(map[string]bool)
+	for _, c := range t.Columns {
+		want[key(c.Name)] = true
+		sc, ok := have[key(c.Name)]
+		if !ok {
+			diff.Added = append(diff.Added, c)
+			continue
+		}
+		nullable := c.Nullable() && !isPK[c.Name]
+		if sc.Nullable != nullable || !sameType(d, d.ColumnType(c), sc.Type) {
+			diff.Changed = append(diff.Changed, ColumnChange{Column: c, Existing: sc, Nullable: nullable})
+		}
+	}
+	for _, c := range existing {
+		if !want[key(c.Name)] {
+			diff.Dropped = append(diff.Dropped, c.Name)
+		}
+	}
+	return diff, nil
+}
+
+// infoSchemaTypes maps the base types used in generated DDL to the names
+// information_schema reports for them, per dialect.
+var infoSchemaTypes = map[string]map[string]string{
+	"mysql": {
+		"boolean": "tinyint",
+		"integer": "int",
+	},
+	"postgres": {
+		"varchar":   "character varying",
+		"bigserial": "bigint",
+		"time":      "time without time zone",
+		"timestamp": "timestamp without time zone",
+	},
+}
+
+// sameType reports whether the existing SQL type matches the column type
+// declared in generated DDL, ignoring lengths, precision and attributes.
+func sameType(d Dialect, declared, existing string) bool {
+	want := baseType(declared)
+	if alias, ok := infoSchemaTypes[d.Name()][want]; ok {
+		want = alias
+	}
+	return want == baseType(existing)
+}
+
+// baseType returns a lowercase SQL type without its parenthesized
+// arguments and without the attributes following it in generated DDL.
+func baseType(typ string) string {
+	typ = strings.ToLower(typ)
+	for {
+		open := strings.Index(typ, "(")
+		end := strings.Index(typ, ")")
+		if open < 0 || end < open {
+			break
+		}
+		typ = typ[:open] + typ[end+1:]
+	}
+	for _, attr := range []string{" unsigned", " auto_increment", " identity", " collate"} {
+		if i := strings.Index(typ, attr); i >= 0 {
+			typ = typ[:i]
+		}
+	}
+	return strings.TrimSpace(typ)
+}
+
+// AlterSQL returns the ALTER TABLE statements that add the missing columns
+// to tableName and, if drop is set, remove the dropped ones. Added columns
+// are nullable since existing rows have no value for them.
+func (diff *SchemaDiff) AlterSQL(d Dialect, tableName string, drop bool) []string {
+	var result []string
+	add := " ADD COLUMN "
+	if d.Name() == SQLServer.Name() {
+		add = " ADD "
+	}
+	for _, c := range diff.Added {
+		result = append(result, "ALTER TABLE "+d.QuoteIdent(tableName)+add+d.QuoteIdent(c.Name)+" "+d.ColumnType(c))
+	}
+	if drop {
+		for _, name := range diff.Dropped {
+			result = append(result, "ALTER TABLE "+d.QuoteIdent(tableName)+" DROP COLUMN "+d.QuoteIdent(name))
+		}
 	}
-	result := fmt.Sprintf("INSERT INTO `%s` VALUES(%s)", tableName, strings.Join(placeHolders, ","))
 	return result
 }
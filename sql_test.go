@@ -0,0 +1,261 @@
+package adt_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmc/adt"
+)
+
+var testColumns = []*adt.Column{
+	{Name: "ID", Type: adt.ColumnTypeAutoIncrement, Offset: 5, Length: 4},
+	{Name: "NAME", Type: adt.ColumnTypeCharacter, Offset: 9, Length: 40},
+	{Name: "BALANCE", Type: adt.ColumnTypeCurrency, Offset: 49, Length: 8, DecimalDigits: 2},
+}
+
+func TestDialectDDL(t *testing.T) {
+	table := &adt.Table{Columns: testColumns}
+	tests := []struct {
+		dialect adt.Dialect
+		want    string
+	}{
+		{adt.MySQL, "CREATE TABLE IF NOT EXISTS `CUST` (\n\n\t`ID` INT UNSIGNED AUTO_INCREMENT NOT NULL,\n\t`NAME` VARCHAR(40) NOT NULL,\n\t`BALANCE` DECIMAL(19,2),\n\tPRIMARY KEY (`ID`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"},
+		{adt.PostgreSQL, "CREATE TABLE IF NOT EXISTS \"CUST\" (\n\n\t\"ID\" BIGSERIAL NOT NULL,\n\t\"NAME\" VARCHAR(40) NOT NULL,\n\t\"BALANCE\" NUMERIC(19,2),\n\tPRIMARY KEY (\"ID\")\n)"},
+		{adt.SQLite, "CREATE TABLE IF NOT EXISTS \"CUST\" (\n\n\t\"ID\" INTEGER NOT NULL,\n\t\"NAME\" TEXT NOT NULL,\n\t\"BALANCE\" NUMERIC,\n\tPRIMARY KEY (\"ID\")\n)"},
+		{adt.SQLServer, "IF OBJECT_ID(N'[CUST]', N'U') IS NULL CREATE TABLE [CUST] (\n\n\t[ID] BIGINT IDENTITY(1,1) NOT NULL,\n\t[NAME] NVARCHAR(40) NOT NULL,\n\t[BALANCE] DECIMAL(19,2),\n\tPRIMARY KEY ([ID])\n)"},
+	}
+	for _, tt := range tests {
+		got, err := table.DialectDDL(tt.dialect, "CUST")
+		if err != nil {
+			t.Fatal(tt.dialect.Name(), err)
+		}
+		if got != tt.want {
+			t.Errorf("%s DDL:\ngot  %q\nwant %q", tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestDialectDDLIndexes(t *testing.T) {
+	table := &adt.Table{
+		Columns: testColumns,
+		Indexes: []*adt.Index{
+			{Name: "CUSTNAME", Columns: []string{"NAME"}, Unique: true},
+			{Columns: []string{"BALANCE", "NAME"}},
+		},
+	}
+	got, err := table.DialectDDL(adt.PostgreSQL, "CUST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE IF NOT EXISTS \"CUST\" (\n\n\t\"ID\" BIGSERIAL NOT NULL,\n\t\"NAME\" VARCHAR(40) NOT NULL,\n\t\"BALANCE\" NUMERIC(19,2),\n\tPRIMARY KEY (\"ID\"),\n\tCONSTRAINT \"CUSTNAME\" UNIQUE (\"NAME\")\n)"
+	if got != want {
+		t.Errorf("DDL:\ngot  %q\nwant %q", got, want)
+	}
+	indexes := table.IndexDDL(adt.PostgreSQL, "CUST")
+	wantIndex := `CREATE INDEX IF NOT EXISTS "idx_CUST_BALANCE_NAME" ON "CUST" ("BALANCE", "NAME")`
+	if len(indexes) != 1 || indexes[0] != wantIndex {
+		t.Errorf("IndexDDL = %q, want [%q]", indexes, wantIndex)
+	}
+}
+
+// TestDialectDDLAutoIncrementKey checks that an AutoIncrement column outside
+// the primary key is still declared unique, since MySQL requires every
+// AUTO_INCREMENT column to lead a key.
+func TestDialectDDLAutoIncrementKey(t *testing.T) {
+	table := &adt.Table{
+		Columns: testColumns,
+		Indexes: []*adt.Index{{Columns: []string{"NAME"}, Primary: true}},
+	}
+	got, err := table.DialectDDL(adt.MySQL, "CUST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE IF NOT EXISTS `CUST` (\n\n\t`ID` INT UNSIGNED AUTO_INCREMENT NOT NULL,\n\t`NAME` VARCHAR(40) NOT NULL,\n\t`BALANCE` DECIMAL(19,2),\n\tPRIMARY KEY (`NAME`),\n\tUNIQUE (`ID`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+	if got != want {
+		t.Errorf("DDL:\ngot  %q\nwant %q", got, want)
+	}
+
+	// An AutoIncrement column already leading a unique index needs no other.
+	table.Indexes = append(table.Indexes, &adt.Index{Name: "CUSTID", Columns: []string{"ID", "NAME"}, Unique: true})
+	if got, err = table.DialectDDL(adt.MySQL, "CUST"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\tCONSTRAINT `CUSTID` UNIQUE (`ID`, `NAME`)\n)"; !strings.Contains(got, want) || strings.Contains(got, "UNIQUE (`ID`)") {
+		t.Errorf("DDL:\ngot  %q\nwant one unique key, %q", got, want)
+	}
+}
+
+func TestDialectInsertSQL(t *testing.T) {
+	table := &adt.Table{Columns: testColumns}
+	tests := []struct {
+		dialect adt.Dialect
+		want    string
+	}{
+		{adt.MySQL, "INSERT INTO `CUST` (`ID`,`NAME`,`BALANCE`) VALUES(?,?,?)"},
+		{adt.PostgreSQL, `INSERT INTO "CUST" ("ID","NAME","BALANCE") VALUES($1,$2,$3)`},
+		{adt.SQLServer, "INSERT INTO [CUST] ([ID],[NAME],[BALANCE]) VALUES(@p1,@p2,@p3)"},
+	}
+	for _, tt := range tests {
+		if got := table.DialectInsertSQL(tt.dialect, "CUST"); got != tt.want {
+			t.Errorf("%s insert: got %q, want %q", tt.dialect.Name(), got, tt.want)
+		}
+	}
+	want := `INSERT INTO "CUST" ("ID","NAME","BALANCE") VALUES($1,$2,$3),($4,$5,$6)`
+	if got := table.BatchInsertSQL(adt.PostgreSQL, "CUST", 2); got != want {
+		t.Errorf("batch insert: got %q, want %q", got, want)
+	}
+}
+
+func TestLookupDialect(t *testing.T) {
+	for name, want := range map[string]adt.Dialect{
+		"mysql":      adt.MySQL,
+		"postgresql": adt.PostgreSQL,
+		"sqlite3":    adt.SQLite,
+		"MSSQL":      adt.SQLServer,
+	} {
+		got, err := adt.LookupDialect(name)
+		if err != nil || got != want {
+			t.Errorf("LookupDialect(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := adt.LookupDialect("oracle"); err == nil {
+		t.Error("expected error for unknown dialect")
+	}
+}
+
+func TestBatchUpsertSQL(t *testing.T) {
+	table := &adt.Table{Columns: testColumns}
+	tests := []struct {
+		dialect adt.Dialect
+		want    string
+	}{
+		{adt.MySQL, "INSERT INTO `CUST` (`ID`,`NAME`,`BALANCE`) VALUES(?,?,?) ON DUPLICATE KEY UPDATE `NAME`=VALUES(`NAME`),`BALANCE`=VALUES(`BALANCE`)"},
+		{adt.PostgreSQL, `INSERT INTO "CUST" ("ID","NAME","BALANCE") VALUES($1,$2,$3) ON CONFLICT ("ID") DO UPDATE SET "NAME"=EXCLUDED."NAME","BALANCE"=EXCLUDED."BALANCE"`},
+	}
+	for _, tt := range tests {
+		got, err := table.BatchUpsertSQL(tt.dialect, "CUST", 1)
+		if err != nil {
+			t.Fatal(tt.dialect.Name(), err)
+		}
+		if got != tt.want {
+			t.Errorf("%s upsert:\ngot  %q\nwant %q", tt.dialect.Name(), got, tt.want)
+		}
+	}
+	if _, err := table.BatchUpsertSQL(adt.SQLServer, "CUST", 1); err != adt.ErrUpsertUnsupported {
+		t.Errorf("SQL Server upsert error = %v, want %v", err, adt.ErrUpsertUnsupported)
+	}
+	noPK := &adt.Table{Columns: testColumns[1:]}
+	if _, err := noPK.BatchUpsertSQL(adt.MySQL, "CUST", 1); err != adt.ErrNoPK {
+		t.Errorf("upsert without primary key error = %v, want %v", err, adt.ErrNoPK)
+	}
+}
+
+func TestIdentitySQL(t *testing.T) {
+	table := &adt.Table{Columns: testColumns}
+	tests := []struct {
+		dialect adt.Dialect
+		on, off string
+		reset   []string
+	}{
+		{dialect: adt.MySQL},
+		{dialect: adt.SQLite},
+		{dialect: adt.PostgreSQL, reset: []string{`SELECT setval(pg_get_serial_sequence('"CUST"', 'ID'), COALESCE(MAX("ID"), 0) + 1, false) FROM "CUST"`}},
+		{dialect: adt.SQLServer, on: "SET IDENTITY_INSERT [CUST] ON", off: "SET IDENTITY_INSERT [CUST] OFF"},
+	}
+	for _, tt := range tests {
+		name := tt.dialect.Name()
+		if got := table.IdentityInsertSQL(tt.dialect, "CUST", true); got != tt.on {
+			t.Errorf("%s identity insert on = %q, want %q", name, got, tt.on)
+		}
+		if got := table.IdentityInsertSQL(tt.dialect, "CUST", false); got != tt.off {
+			t.Errorf("%s identity insert off = %q, want %q", name, got, tt.off)
+		}
+		if got := table.ResetSequenceSQL(tt.dialect, "CUST"); !reflect.DeepEqual(got, tt.reset) {
+			t.Errorf("%s reset sequence = %q, want %q", name, got, tt.reset)
+		}
+	}
+	noPK := &adt.Table{Columns: testColumns[1:]}
+	if got := noPK.IdentityInsertSQL(adt.SQLServer, "CUST", true); got != "" {
+		t.Errorf("identity insert without AutoIncrement = %q", got)
+	}
+	if got := noPK.ResetSequenceSQL(adt.PostgreSQL, "CUST"); got != nil {
+		t.Errorf("reset sequence without AutoIncrement = %q", got)
+	}
+}
+
+func TestSchemaDiff(t *testing.T) {
+	table := &adt.Table{Columns: testColumns}
+	diff, err := table.Diff(adt.MySQL, []adt.SQLColumn{
+		{Name: "id", Type: "int"},
+		{Name: "name", Type: "varchar"},
+		{Name: "OLDCOL", Type: "text", Nullable: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Name != "BALANCE" {
+		t.Errorf("Added = %v, want [BALANCE]", diff.Added)
+	}
+	if len(diff.Dropped) != 1 || diff.Dropped[0] != "OLDCOL" {
+		t.Errorf("Dropped = %v, want [OLDCOL]", diff.Dropped)
+	}
+	if len(diff.Changed) != 0 {
+		t.Errorf("Changed = %v, want none", diff.Changed)
+	}
+	got := diff.AlterSQL(adt.MySQL, "CUST", true)
+	want := []string{
+		"ALTER TABLE `CUST` ADD COLUMN `BALANCE` DECIMAL(19,2)",
+		"ALTER TABLE `CUST` DROP COLUMN `OLDCOL`",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AlterSQL = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AlterSQL[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		name     string
+		dialect  adt.Dialect
+		existing []adt.SQLColumn
+		added    int
+		dropped  int
+		changed  []string
+	}{
+		{"mysql", adt.MySQL, []adt.SQLColumn{
+			{Name: "ID", Type: "int"}, {Name: "NAME", Type: "varchar"}, {Name: "BALANCE", Type: "decimal", Nullable: true},
+		}, 0, 0, nil},
+		{"postgres", adt.PostgreSQL, []adt.SQLColumn{
+			{Name: "ID", Type: "bigint"}, {Name: "NAME", Type: "character varying"}, {Name: "BALANCE", Type: "numeric", Nullable: true},
+		}, 0, 0, nil},
+		{"sqlserver", adt.SQLServer, []adt.SQLColumn{
+			{Name: "ID", Type: "bigint"}, {Name: "NAME", Type: "nvarchar"}, {Name: "BALANCE", Type: "decimal", Nullable: true},
+		}, 0, 0, nil},
+		{"sqlite", adt.SQLite, []adt.SQLColumn{
+			{Name: "ID", Type: "INTEGER"}, {Name: "NAME", Type: "TEXT"}, {Name: "BALANCE", Type: "NUMERIC", Nullable: true},
+		}, 0, 0, nil},
+		{"postgres case", adt.PostgreSQL, []adt.SQLColumn{
+			{Name: "id", Type: "bigint"}, {Name: "NAME", Type: "character varying"}, {Name: "BALANCE", Type: "numeric", Nullable: true},
+		}, 1, 1, nil},
+		{"type and nullability", adt.PostgreSQL, []adt.SQLColumn{
+			{Name: "ID", Type: "bigint"}, {Name: "NAME", Type: "text"}, {Name: "BALANCE", Type: "numeric"},
+		}, 0, 0, []string{"NAME", "BALANCE"}},
+	}
+	for _, tt := range tests {
+		diff, err := table.Diff(tt.dialect, tt.existing)
+		if err != nil {
+			t.Fatal(tt.name, err)
+		}
+		var changed []string
+		for _, c := range diff.Changed {
+			changed = append(changed, c.Column.Name)
+		}
+		if len(diff.Added) != tt.added || len(diff.Dropped) != tt.dropped || !reflect.DeepEqual(changed, tt.changed) {
+			t.Errorf("%s: added %v, dropped %v, changed %v; want %d added, %d dropped, changed %v",
+				tt.name, diff.Added, diff.Dropped, changed, tt.added, tt.dropped, tt.changed)
+		}
+	}
+}
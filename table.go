@@ -24,6 +24,8 @@ const (
 var (
 	ErrMagicHeaderNotFound = errors.New("adt: magic header missing")
 	ErrMultiplePKs         = errors.New("adt: multiple primary keys")
+	ErrNoPK                = errors.New("adt: no primary key")
+	ErrNoMemoFile          = errors.New("adt: memo file missing")
 )
 
 type Table struct {
@@ -32,8 +34,13 @@ type Table struct {
 	DataOffset   uint16
 	RecordLength uint32
 	Columns      []*Column
-	data         io.ReadSeeker
-	memoData     io.ReadSeeker
+	// Indexes lists the table's index tags. Index files (.ADI) and data
+	// dictionaries (.ADD) are not decoded, so Indexes is empty after Open;
+	// callers that know a table's indexes (adt2sql from its -index and
+	// -unique flags, for example) fill them in before generating DDL.
+	Indexes  []*Index
+	data     io.ReadSeeker
+	memoData io.ReadSeeker
 }
 
 func TableFromPath(filePath string) (*Table, error) {
@@ -43,11 +50,18 @@ func TableFromPath(filePath string) (*Table, error) {
 	}
 	ext := filepath.Ext(filePath)
 	admPath := filePath[:len(filePath)-len(ext)] + ".ADM"
-	adm, _ := os.Open(admPath)
 	// adm isn't required.
+	var adm io.ReadSeeker
+	if f, err := os.Open(admPath); err == nil {
+		adm = f
+	}
 	table, err := FromReaders(adt, adm)
+	if err != nil {
+		adt.Close()
+		return nil, err
+	}
 	table.Name = filepath.Base(filePath)
-	return table, err
+	return table, nil
 }
 
 func FromReaders(adtContent io.ReadSeeker, admContent io.ReadSeeker) (*Table, error) {
@@ -82,6 +96,17 @@ func FromReaders(adtContent io.ReadSeeker, admContent io.ReadSeeker) (*Table, er
 	return table, nil
 }
 
+// Close closes the table's data and memo readers if they are io.Closers.
+func (t *Table) Close() error {
+	if c, ok := t.memoData.(io.Closer); ok {
+		c.Close()
+	}
+	if c, ok := t.data.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func (t *Table) columnCount() int {
 	return int((t.DataOffset - HeaderLength) / 200)
 }
@@ -108,13 +133,49 @@ func (t *Table) Get(record int) (Record, error) {
 	return t.readRecord()
 }
 
+// readRaw reads n consecutive records starting at record.
+func (t *Table) readRaw(record, n int) ([]byte, error) {
+	if _, err := t.data.Seek(int64(int(t.DataOffset)+int(t.RecordLength)*record), 0); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, n*int(t.RecordLength))
+	if _, err := io.ReadFull(t.data, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// RawRecord returns the undecoded bytes of the given record, including its
+// leading flag bytes.
+func (t *Table) RawRecord(record int) ([]byte, error) {
+	return t.readRaw(record, 1)
+}
+
+// IsDeleted reports whether the given record is marked as deleted.
+func (t *Table) IsDeleted(record int) (bool, error) {
+	if _, err := t.data.Seek(int64(int(t.DataOffset)+int(t.RecordLength)*record), 0); err != nil {
+		return false, err
+	}
+	flag := make([]byte, 1)
+	if _, err := io.ReadFull(t.data, flag); err != nil {
+		return false, err
+	}
+	return flag[0] == RecordFlagDeleted, nil
+}
+
 func (t *Table) readRecord() (Record, error) {
-	r := Record{}
 	bytes := make([]byte, t.RecordLength)
 	if r, err := io.ReadFull(t.data, bytes); err != nil {
 		log.Warn("didn't read enough: ", r, err)
 		return nil, err
 	}
+	return t.DecodeRecord(bytes)
+}
+
+// DecodeRecord returns the values of a record read with RawRecord, reading
+// its memo fields from the memo file.
+func (t *Table) DecodeRecord(bytes []byte) (Record, error) {
+	r := Record{}
 	if string(bytes[:len(RecordMagicHeader)]) != RecordMagicHeader {
 		//return nil, ErrMagicHeaderNotFound
 	}
@@ -124,9 +185,8 @@ func (t *Table) readRecord() (Record, error) {
 		//valueBytes := bytes[column.Offset : column.Offset+column.Length]
 
 		if asMemo, ok := value.(MemoField); ok {
-			t.memoData.Seek(int64(asMemo.BlockOffset)*8, 0)
-			data := make([]byte, asMemo.Length)
-			if _, err := io.ReadFull(t.memoData, data); err != nil {
+			data, err := t.readMemo(asMemo)
+			if err != nil {
 				log.Warnln("didn't read enough for memo field", column.Name, err)
 				return nil, nil
 			}
@@ -141,15 +201,39 @@ func (t *Table) readRecord() (Record, error) {
 	return r, nil
 }
 
+// readMemo reads the contents of a memo field from the .ADM file.
+func (t *Table) readMemo(m MemoField) ([]byte, error) {
+	if t.memoData == nil {
+		return nil, ErrNoMemoFile
+	}
+	if _, err := t.memoData.Seek(int64(m.BlockOffset)*8, 0); err != nil {
+		return nil, err
+	}
+	data := make([]byte, m.Length)
+	if _, err := io.ReadFull(t.memoData, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// DecodeString converts raw character data to a string, mapping each byte
+// to the code point of the same value.
+func DecodeString(b []byte) string {
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		runes = append(runes, rune(c))
+	}
+	return string(runes)
+}
+
 func ReadValue(src []byte, column *Column) (interface{}, error) {
+	if int(column.Length) < column.Type.width() {
+		return nil, ErrColumnWidth
+	}
 	valueBytes := src[column.Offset : column.Offset+column.Length]
 	switch column.Type {
-	case ColumnTypeCharacter:
-		runes := make([]rune, 0, len(valueBytes))
-		for _, b := range valueBytes {
-			runes = append(runes, rune(b))
-		}
-		return strings.Trim(string(runes), " \u0000"), nil
+	case ColumnTypeCharacter, ColumnTypeCiCharacter:
+		return strings.Trim(DecodeString(valueBytes), " \u0000"), nil
 	case ColumnTypeShortInt:
 		var value int16
 		err := binary.Read(bytes.NewReader(valueBytes), binary.LittleEndian, &value)
@@ -176,6 +260,10 @@ func ReadValue(src []byte, column *Column) (interface{}, error) {
 		var value uint32
 		err := binary.Read(bytes.NewReader(valueBytes), binary.LittleEndian, &value)
 		return value, err
+	case ColumnTypeRowVersion:
+		var value uint64
+		err := binary.Read(bytes.NewReader(valueBytes), binary.LittleEndian, &value)
+		return value, err
 	case ColumnTypeBool:
 		var value bool
 		if src[column.Offset : column.Offset+column.Length][0] == 'T' {
@@ -190,7 +278,7 @@ func ReadValue(src []byte, column *Column) (interface{}, error) {
 			return time.Duration(0), nil
 		}
 		return time.Millisecond * time.Duration(n), nil
-	case ColumnTypeTimestamp:
+	case ColumnTypeTimestamp, ColumnTypeModTime:
 		buf := src[column.Offset : column.Offset+column.Length]
 		i := binary.LittleEndian.Uint32(buf[:4])
 		j := binary.LittleEndian.Uint32(buf[4:])
@@ -218,9 +306,6 @@ func ReadValue(src []byte, column *Column) (interface{}, error) {
 		}
 		return value, err
 	default:
-		value := make([]byte, column.Length)
-		copy(value, src[column.Offset:column.Offset+column.Length])
 		return nil, fmt.Errorf("adt ReadValue: %s not implemented", column.Type)
-		return value, nil
 	}
 }
@@ -25,3 +25,23 @@ func TestTableRead(t *testing.T) {
 		}
 	}
 }
+
+func TestReadValueCiCharacter(t *testing.T) {
+	c := &adt.Column{Name: "CODE", Type: adt.ColumnTypeCiCharacter, Offset: 5, Length: 6}
+	rec := append(make([]byte, 5), "AbC\x00  "...)
+	v, err := adt.ReadValue(rec, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "AbC" {
+		t.Errorf("ReadValue = %q, want %q", v, "AbC")
+	}
+}
+
+func TestNullableCharacter(t *testing.T) {
+	for _, typ := range []adt.ColumnType{adt.ColumnTypeCharacter, adt.ColumnTypeCiCharacter} {
+		if (&adt.Column{Type: typ}).Nullable() {
+			t.Errorf("%v columns are nullable; ReadValue never returns nil for them", typ)
+		}
+	}
+}
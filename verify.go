@@ -0,0 +1,225 @@
+package adt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Kinds of Problem found by Verify.
+const (
+	ProblemHeader        = "header"         // inconsistent or unparsable header
+	ProblemUnreadable    = "unreadable"     // file missing or failing to read
+	ProblemFileSize      = "file_size"      // file size disagrees with the header
+	ProblemColumnType    = "column_type"    // column type ReadValue can't decode
+	ProblemColumnBounds  = "column_bounds"  // column outside the record
+	ProblemColumnOverlap = "column_overlap" // column overlapping another
+	ProblemColumnWidth   = "column_width"   // column too narrow for its type
+	ProblemRecordFlag    = "record_flag"    // unknown record flag byte
+	ProblemMemoPointer   = "memo_pointer"   // memo past the end of the .ADM file
+	ProblemInvalidDate   = "invalid_date"   // date, time or timestamp out of range
+)
+
+// RecordFlagActive is the value of the first byte of a record in use.
+const RecordFlagActive = 0x04
+
+// MaxProblems is the number of problems Verify reports before giving up.
+const MaxProblems = 1000
+
+// Julian day numbers of 0001-01-01 and 9999-12-31, the range of ADS dates.
+const (
+	minJulianDay = 1721426
+	maxJulianDay = 5373484
+)
+
+// Problem describes one inconsistency found by Verify.
+type Problem struct {
+	Kind string `json:"kind"`
+	// Record is the index of the record involved, or -1 for problems with
+	// the table's structure.
+	Record  int    `json:"record"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	s := p.Kind
+	if p.Record >= 0 {
+		s += fmt.Sprintf(" record %d", p.Record)
+	}
+	if p.Column != "" {
+		s += " column " + p.Column
+	}
+	return s + ": " + p.Message
+}
+
+// VerifyReport is the result of Table.Verify.
+type VerifyReport struct {
+	Table        string `json:"table"`
+	FileSize     int64  `json:"file_size"`
+	ExpectedSize int64  `json:"expected_size"`
+	// MemoSize is the size of the .ADM file, or -1 if there is none.
+	MemoSize int64     `json:"memo_size"`
+	Records  int       `json:"records"`
+	Checked  int       `json:"checked"`
+	Deleted  int       `json:"deleted"`
+	Problems []Problem `json:"problems"`
+	// Truncated is set when verification stopped after MaxProblems.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// OK reports whether no problems were found.
+func (r *VerifyReport) OK() bool { return len(r.Problems) == 0 }
+
+func (r *VerifyReport) add(kind string, record int, column, format string, args ...interface{}) {
+	if len(r.Problems) >= MaxProblems {
+		r.Truncated = true
+		return
+	}
+	r.Problems = append(r.Problems, Problem{Kind: kind, Record: record, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+// scanBatch is the number of records read at a time when scanning a table.
+const scanBatch = 1024
+
+// Verify checks the table's header, column layout and every record for
+// inconsistencies: a file size that disagrees with the header, columns
+// outside the record or overlapping each other, unknown record flags, memo
+// pointers past the end of the .ADM file and out of range dates and times.
+// The returned error is set only when the files can't be read at all;
+// damage is described by the report.
+func (t *Table) Verify() (*VerifyReport, error) {
+	r := &VerifyReport{
+		Table:        t.Name,
+		ExpectedSize: int64(t.DataOffset) + int64(t.RecordLength)*int64(t.RecordCount),
+		MemoSize:     -1,
+		Records:      int(t.RecordCount),
+		Problems:     []Problem{},
+	}
+	size, err := t.data.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	r.FileSize = size
+	if t.memoData != nil {
+		if r.MemoSize, err = t.memoData.Seek(0, io.SeekEnd); err != nil {
+			r.MemoSize = -1
+		}
+	}
+
+	if t.DataOffset < HeaderLength || (t.DataOffset-HeaderLength)%ColumnDescriptorLength != 0 {
+		r.add(ProblemHeader, -1, "", "data offset %d does not follow a whole number of column descriptors", t.DataOffset)
+	}
+	if t.RecordLength == 0 {
+		r.add(ProblemHeader, -1, "", "record length is zero")
+		return r, nil
+	}
+	if size != r.ExpectedSize {
+		r.add(ProblemFileSize, -1, "", "file is %d bytes, header implies %d", size, r.ExpectedSize)
+	}
+	t.verifyColumns(r)
+
+	available := 0
+	if size > int64(t.DataOffset) {
+		available = int((size - int64(t.DataOffset)) / int64(t.RecordLength))
+	}
+	if available > int(t.RecordCount) {
+		available = int(t.RecordCount)
+	}
+	for start := 0; start < available && !r.Truncated; start += scanBatch {
+		n := available - start
+		if n > scanBatch {
+			n = scanBatch
+		}
+		raw, err := t.readRaw(start, n)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n && !r.Truncated; i++ {
+			rec := raw[i*int(t.RecordLength) : (i+1)*int(t.RecordLength)]
+			t.verifyRecord(r, start+i, rec)
+			r.Checked++
+		}
+	}
+	return r, nil
+}
+
+func (t *Table) verifyColumns(r *VerifyReport) {
+	columns := make([]*Column, len(t.Columns))
+	copy(columns, t.Columns)
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Offset < columns[j].Offset })
+	for i, c := range columns {
+		if !c.Type.known() {
+			r.add(ProblemColumnType, -1, c.Name, "unknown column type %d", c.Type)
+		}
+		if w := c.Type.width(); int(c.Length) < w {
+			r.add(ProblemColumnWidth, -1, c.Name, "%d bytes, %s needs %d", c.Length, strings.TrimPrefix(c.Type.String(), "ColumnType"), w)
+		}
+		end := int(c.Offset) + int(c.Length)
+		if c.Offset < uint16(len(RecordMagicHeader)+1) || end > int(t.RecordLength) {
+			r.add(ProblemColumnBounds, -1, c.Name, "bytes %d-%d are outside the %d byte record", c.Offset, end, t.RecordLength)
+		}
+		if i > 0 {
+			prev := columns[i-1]
+			if int(prev.Offset)+int(prev.Length) > int(c.Offset) {
+				r.add(ProblemColumnOverlap, -1, c.Name, "starts at %d inside %s (%d-%d)", c.Offset, prev.Name, prev.Offset, int(prev.Offset)+int(prev.Length))
+			}
+		}
+	}
+}
+
+func (t *Table) verifyRecord(r *VerifyReport, record int, raw []byte) {
+	switch raw[0] {
+	case RecordFlagActive:
+	case RecordFlagDeleted:
+		r.Deleted++
+	default:
+		r.add(ProblemRecordFlag, record, "", "unknown flag byte 0x%02x", raw[0])
+	}
+	for _, c := range t.Columns {
+		end := int(c.Offset) + int(c.Length)
+		if end > len(raw) || int(c.Length) < c.Type.width() {
+			continue // reported by verifyColumns
+		}
+		b := raw[c.Offset:end]
+		switch c.Type {
+		case ColumnTypeMemo:
+			block, length := binary.LittleEndian.Uint32(b), binary.LittleEndian.Uint16(b[4:])
+			if length == 0 {
+				continue
+			}
+			if r.MemoSize < 0 {
+				r.add(ProblemMemoPointer, record, c.Name, "%d byte memo but no memo file", length)
+			} else if memoEnd := int64(block)*8 + int64(length); memoEnd > r.MemoSize {
+				r.add(ProblemMemoPointer, record, c.Name, "memo ends at %d, past the %d byte memo file", memoEnd, r.MemoSize)
+			}
+		case ColumnTypeDate:
+			verifyDay(r, record, c, int32(binary.LittleEndian.Uint32(b)))
+		case ColumnTypeTimestamp, ColumnTypeModTime:
+			day := int32(binary.LittleEndian.Uint32(b))
+			if day == 0 {
+				continue
+			}
+			verifyDay(r, record, c, day)
+			verifyMillis(r, record, c, int32(binary.LittleEndian.Uint32(b[4:])))
+		case ColumnTypeTime:
+			if ms := int32(binary.LittleEndian.Uint32(b)); ms != -1 {
+				verifyMillis(r, record, c, ms)
+			}
+		}
+	}
+}
+
+func verifyDay(r *VerifyReport, record int, c *Column, day int32) {
+	if day != 0 && (day < minJulianDay || day > maxJulianDay) {
+		r.add(ProblemInvalidDate, record, c.Name, "julian day %d is outside years 1-9999", day)
+	}
+}
+
+func verifyMillis(r *VerifyReport, record int, c *Column, ms int32) {
+	if ms < 0 || ms >= 24*60*60*1000 {
+		r.add(ProblemInvalidDate, record, c.Name, "%d milliseconds is not a time of day", ms)
+	}
+}
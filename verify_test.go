@@ -0,0 +1,110 @@
+package adt_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/adt"
+)
+
+func TestVerify(t *testing.T) {
+	columns := []*adt.Column{
+		{Name: "ID", Type: adt.ColumnTypeInt, Offset: 5, Length: 4},
+		{Name: "BORN", Type: adt.ColumnTypeDate, Offset: 9, Length: 4},
+		{Name: "NOTE", Type: adt.ColumnTypeMemo, Offset: 13, Length: 9},
+	}
+	record := func(id, day int32, block uint32, length uint16) []byte {
+		b := append(le32(id), le32(day)...)
+		b = append(b, le32(int32(block))...)
+		return append(b, byte(length), byte(length>>8), 0, 0, 0)
+	}
+	records := [][]byte{
+		record(1, 2440588, 0, 0),
+		record(2, 99, 0, 0),
+		record(3, 2440589, 1, 4),
+	}
+	memo := make([]byte, 12)
+
+	tests := []struct {
+		name    string
+		corrupt func(b []byte) []byte
+		want    []string
+	}{
+		{"clean", func(b []byte) []byte { return b }, []string{
+			adt.ProblemInvalidDate,
+		}},
+		{"flag", func(b []byte) []byte {
+			b[len(b)-3*22] = 0x07 // first record's flag byte
+			return b
+		}, []string{
+			adt.ProblemRecordFlag,
+			adt.ProblemInvalidDate,
+		}},
+		{"truncated", func(b []byte) []byte { return b[:len(b)-10] }, []string{
+			adt.ProblemFileSize,
+			adt.ProblemInvalidDate,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.corrupt(adtBytes(columns, records))
+			table, err := adt.FromReaders(bytes.NewReader(data), bytes.NewReader(memo))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := table.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range r.Problems {
+				got = append(got, p.Kind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems = %v, want kinds %v", r.Problems, tt.want)
+			}
+			if r.MemoSize != int64(len(memo)) {
+				t.Errorf("MemoSize = %d, want %d", r.MemoSize, len(memo))
+			}
+		})
+	}
+
+	// A memo pointer past the end of the .ADM and a short record.
+	data := adtBytes(columns, records)
+	table, err := adt.FromReaders(bytes.NewReader(data), bytes.NewReader(memo[:10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := table.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Problems) != 2 || r.Problems[1].Kind != adt.ProblemMemoPointer || r.Problems[1].Record != 2 {
+		t.Errorf("problems = %v, want a memo pointer problem in record 2", r.Problems)
+	}
+	if r.Checked != 3 || r.OK() {
+		t.Errorf("Checked = %d, OK = %v", r.Checked, r.OK())
+	}
+}
+
+func TestVerifyColumns(t *testing.T) {
+	columns := []*adt.Column{
+		{Name: "A", Type: adt.ColumnTypeInt, Offset: 5, Length: 4},
+		{Name: "B", Type: adt.ColumnTypeInt, Offset: 7, Length: 4},
+		{Name: "C", Type: adt.ColumnType(99), Offset: 15, Length: 4},
+	}
+	table := testADT(t, columns, nil)
+	r, err := table.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range r.Problems {
+		got = append(got, p.Kind+" "+p.Column)
+	}
+	want := []string{"column_overlap B", "column_type C", "column_bounds C"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems = %v, want %v", got, want)
+	}
+}